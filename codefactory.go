@@ -1,9 +1,13 @@
 package codefactory
 
 import (
+	"context"
 	"errors"
+	"math"
+	"math/big"
 	"math/rand"
 	"strings"
+	"time"
 	"unicode"
 )
 
@@ -15,27 +19,63 @@ const (
 	defaultCustom    = ""
 	defaultPrefix    = ""
 	defaultSuffix    = ""
-	validFormatChars = "xdlwupac"
+	validFormatChars = "xdlwupacstnymeigrvkb"
+
+	maxRetriesBase   = 4
+	maxRetriesSigmas = 4
+	maxNumCodes      = 1E7
+	progressInterval = 1 << 14
+	denseFactor      = 2
+	smallSpace       = 1 << 16
+)
 
-	maxRetriesPercent = 10
-	maxRetriesBase    = 4
-	maxNumCodes       = 1E7
+// Errors returned by the CodeFactory.  Some are returned wrapped in a
+// CharError or a CountError with more detail, so they should be checked for
+// with errors.Is.
+var (
+	ErrDuplicates         = errors.New("can't include duplicates")
+	ErrWhitespace         = errors.New("can't include whitespace")
+	ErrAlreadyExist       = errors.New("can't extend with characters that already exist")
+	ErrInvalidFormat      = errors.New("invalid format character")
+	ErrNotLetter          = errors.New("not a letter")
+	ErrNotLatin1          = errors.New("can only extend with Latin1 letters and digits")
+	ErrMaxRetriesExceeded = errors.New("too many duplicate codes generated. Consider using a longer code")
+	ErrInfeasible         = errors.New("more duplicate codes expected than the retry policy allows")
+	ErrTooManyCodes       = errors.New("too many codes to generate with given settings")
+	ErrLeadingWhitespace  = errors.New("a prefix may not have leading whitespace")
+	ErrTrailingWhitespace = errors.New("a suffix may not have trailing whitespace")
+	ErrNoCharacters       = errors.New("no characters can be generated with an empty set")
+	ErrInvalidCode        = errors.New("code does not match the format")
+	ErrNoKey              = errors.New("a key must be set to generate signed codes")
+	ErrBadSignature       = errors.New("code signature is not valid")
+	ErrFieldExists        = errors.New("a field with that name already exists")
+	ErrUnknownField       = errors.New("no field with that name")
+	ErrFieldRange         = errors.New("field value out of range")
+	ErrFieldsTooLarge     = errors.New("fields don't fit in the format")
+	ErrInvalidShard       = errors.New("invalid shard for the space of codes")
+	ErrTimePrecision      = errors.New("time precision must be positive")
+	ErrTimeOverflow       = errors.New("time doesn't fit in the time characters")
+	ErrNoTime             = errors.New("format has no time characters")
+	ErrCounterRange       = errors.New("invalid counter start or step")
+	ErrCounterOverflow    = errors.New("counter doesn't fit in the counter characters")
+	ErrIDRange            = errors.New("ID out of range for the space of codes")
+	ErrNoSalt             = errors.New("a salt must be set to encode IDs")
+	ErrInvalidDistance    = errors.New("invalid minimum distance or metric")
+	ErrNoField            = errors.New("code characters don't fit a finite field")
+	ErrNoParity           = errors.New("format has no parity characters")
+	ErrUncorrectable      = errors.New("too many errors to correct")
+	ErrBadParity          = errors.New("code parity characters are not valid")
+	ErrInvalidWord        = errors.New("words must be distinct and made of letters")
+	ErrWordsJoined        = errors.New("words that start with other words must be separated in the format")
+	ErrInvalidPolicy      = errors.New("invalid policy for the character sets")
+	ErrPolicySet          = errors.New("not supported while a policy is set")
+	ErrNoVanity           = errors.New("substring doesn't fit the format")
+	ErrNoSamples          = errors.New("no sample codes to infer from")
+	ErrInvalidCount       = errors.New("number of codes can't be negative")
 )
 
 var (
-	errDuplicates         = errors.New("can't include duplicates")
-	errWhitespace         = errors.New("can't include whitespace")
-	errAlreadyExist       = errors.New("can't extend with characters that already exist")
-	errNotUpper           = errors.New("not uppercase letters")
-	errNotLower           = errors.New("not lower letters")
-	errInvalidFormat      = errors.New("invalid format character")
-	errNotLetter          = errors.New("not a letter")
-	errNotLatin1          = errors.New("can only extend with Latin1 letters and digits")
-	errMaxRetriesExceeded = errors.New("too many duplicate codes generated. Consider using a longer code")
-	errTooManyCodes       = errors.New("too many codes to generate with given settings")
-	errLeadingWhitespace  = errors.New("a prefix may not have leading whitespace")
-	errTrailingWhitespace = errors.New("a suffix may not have trailing whitespace")
-	errNoCharacters       = errors.New("no characters can be generated with an empty set")
+	defaultRetryPolicy = BirthdayPolicy{Sigmas: maxRetriesSigmas, Base: maxRetriesBase}
 
 	// AllValidUppercase is the set of all valid Latin1 uppercase characters as
 	// defined by unicode.
@@ -73,8 +113,39 @@ type CodeFactory struct {
 	upper  string
 	custom string
 	format string
+	escape rune // see SetEscape
 	prefix string
 	suffix string
+	key    []byte
+	salt   []byte
+	fields []field
+
+	progress func(Progress)
+	rnd      *rand.Rand
+	seed     int64
+	retry    RetryPolicy
+
+	// time characters, see SetTimePrecision
+	precision time.Duration
+	now       func() time.Time
+
+	// counter characters, see SetCounter
+	counter int64
+	step    int64
+
+	// minimum distance between codes, see SetMinDistance
+	minDist int
+	metric  Metric
+	issued  []string // without the prefix and suffix, sorted
+
+	blocklist []string
+	words     []string // see SetWords
+	policy    *Policy  // see SetPolicy
+	reserved  map[string]bool
+
+	// normalization rules, see Normalize
+	aliases    map[rune]rune
+	strictCase bool
 }
 
 // New generates a new default CodeFactory.
@@ -104,6 +175,7 @@ func NewReadable() *CodeFactory {
 // uppercase, lowercase, or numbers sets. It does not affect the prefix,
 // suffix, or custom set.
 func (cf *CodeFactory) Exclude(s string) error {
+	i := 0
 	for _, v := range s {
 		switch {
 		case unicode.IsDigit(v):
@@ -113,18 +185,19 @@ func (cf *CodeFactory) Exclude(s string) error {
 		case unicode.IsUpper(v): // upper
 			cf.upper = strings.Replace(cf.upper, string(v), "", 1)
 		default:
-			return errNotLetter
+			return charError(ErrNotLetter, s, i)
 		}
+		i++
 	}
 	return nil
 }
 
 // SetCustom sets the custom set of characters.
 func (cf *CodeFactory) SetCustom(s string) error {
-	if hasWhitespace(s) {
-		return errWhitespace
-	} else if hasDuplicates(s) {
-		return errDuplicates
+	if i := firstWhitespace(s); i >= 0 {
+		return charError(ErrWhitespace, s, i)
+	} else if i := firstDuplicate(s); i >= 0 {
+		return charError(ErrDuplicates, s, i)
 	}
 	cf.custom = s
 	return nil
@@ -141,25 +214,54 @@ func (cf *CodeFactory) SetCustom(s string) error {
 //  - p = any uppercase letter or number
 //  - a = any uppercase or lowercase letter
 //  - c = any character in the custom set
+//  - v = any vowel in the lowercase set, or the uppercase set if there are no
+//  lowercase letters
+//  - k = any consonant in the same set as v
+//  - b = a word from the word list, see SetWords
+//  - s = a signature character from the same set as x, see SetKey
+//  - t = a time character from the same set as x, see SetTimePrecision
+//  - n = a digit of the counter, see SetCounter
+//  - y, m, e = a digit of the year, month, or day of the month
+//  - g, i = a digit of the ISO 8601 year or week
+//  - r = a parity character from the same set as x, see Correct
 //  - any punctuation, symbol, or whitespace, which will simply be printed in
 //  the final code
 // Other than the characters given, the format string may include symbols,
 // spaces, and punctuation.
 //
-// If letters are needed, they should be set in the prefix or suffix.
+// A character set with SetEscape prints the character after it as is, so
+// letters and numbers can be printed within the code.
 func (cf *CodeFactory) SetFormat(s string) error {
-	for _, v := range s {
+	if err := checkFormat(s, cf.escape); err != nil {
+		return err
+	}
+	cf.format = s
+	return nil
+}
+
+// checkFormat returns an error if s isn't a valid format with the escape
+// character esc, or with no escape character if esc is 0.
+func checkFormat(s string, esc rune) error {
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		v := runes[i]
+		if v == esc && esc != 0 {
+			i++
+			if i == len(runes) || !unicode.IsGraphic(runes[i]) {
+				return charError(ErrInvalidFormat, s, i-1)
+			}
+			continue
+		}
 		// if not punctuation, symbol, or space
 		if !unicode.IsPunct(v) && !unicode.IsSymbol(v) && v != ' ' && !unicode.IsLetter(v) {
-			return errInvalidFormat
+			return charError(ErrInvalidFormat, s, i)
 		}
 		if unicode.IsLetter(v) {
 			if !isIncludedIn(validFormatChars, v) {
-				return errInvalidFormat
+				return charError(ErrInvalidFormat, s, i)
 			}
 		}
 	}
-	cf.format = s
 	return nil
 }
 
@@ -169,39 +271,39 @@ func (cf *CodeFactory) SetFormat(s string) error {
 //This allows the  addition of common letters from Latin script based character
 //sets such as  German, Spanish, Hungarian, and Norwegian.
 func (cf *CodeFactory) ExtendLetters(s string) error {
-	if !allLatin1(s) {
-		return errNotLatin1
-	} else if hasWhitespace(s) {
-		return errWhitespace
-	} else if hasDuplicates(s) {
-		return errDuplicates
+	if i := firstNotLatin1(s); i >= 0 {
+		return charError(ErrNotLatin1, s, i)
+	} else if i := firstWhitespace(s); i >= 0 {
+		return charError(ErrWhitespace, s, i)
+	} else if i := firstDuplicate(s); i >= 0 {
+		return charError(ErrDuplicates, s, i)
 	}
 
 	currentUpper := cf.upper
 	currentLower := cf.lower
 
-	for _, v := range s {
+	for i, v := range []rune(s) {
 
 		switch {
 		// lowercase
 		case unicode.IsLower(v):
 			if strings.Contains(cf.lower, string(v)) {
 				cf.lower = currentLower
-				return errAlreadyExist
+				return charError(ErrAlreadyExist, s, i)
 			}
 			cf.lower += string(v)
 
 		case unicode.IsUpper(v):
 			if strings.Contains(cf.upper, string(v)) {
 				cf.upper = currentUpper
-				return errAlreadyExist
+				return charError(ErrAlreadyExist, s, i)
 			}
 			cf.upper += string(v)
 
 		default:
 			cf.upper = currentUpper
 			cf.lower = currentLower
-			return errNotLetter
+			return charError(ErrNotLetter, s, i)
 
 		}
 	}
@@ -226,7 +328,7 @@ func (cf *CodeFactory) SetPrefix(s string) error {
 
 	// else check that there is no leading whitespace
 	if unicode.IsSpace(rune(s[0])) {
-		return errLeadingWhitespace
+		return ErrLeadingWhitespace
 	}
 	cf.prefix = s
 	return nil
@@ -250,7 +352,7 @@ func (cf *CodeFactory) SetSuffix(s string) error {
 
 	// else check that there is no trailing whitespace
 	if unicode.IsSpace(rune(s[len(s)-1])) {
-		return errTrailingWhitespace
+		return ErrTrailingWhitespace
 	}
 	cf.suffix = s
 	return nil
@@ -259,68 +361,116 @@ func (cf *CodeFactory) SetSuffix(s string) error {
 // MaxCodes returns the maximum number of codes that can be
 // generated with the current CodeFactory settings.
 //
-// In general it will not be possible to generate this full set using
-// codefactory as this would cause too many collisions, and require different
-// logic to complete.
+// Requests for at least half of this number are generated by drawing codes
+// without replacement, so it is possible to generate the full set.
+//
+// If the format contains counter characters, each code takes the next value of
+// the counter, so this is the number of counter values left.  If a minimum
+// distance has been set with SetMinDistance, this is an estimate.  Reserved
+// codes that could be generated aren't counted, and nor are blocked codes
+// where SetBlocklist says so.
 func (cf *CodeFactory) MaxCodes() int64 {
 
-	// lengths for optimisation
-	lenX := int64(len(cf.num + cf.upper + cf.lower))
-	lenD := int64(len(cf.num))
-	lenL := int64(len(cf.lower))
-	lenW := int64(len(cf.lower + cf.num))
-	lenU := int64(len(cf.upper))
-	lenP := int64(len(cf.upper + cf.num))
-	lenA := int64(len(cf.upper + cf.lower))
-	lenC := int64(len(cf.custom))
-
-	max := int64(1)
-
-	for _, v := range cf.format {
-		if unicode.IsLower(v) {
-			switch v {
-			case 'x': // any
-				max *= lenX
-			case 'd': // digits
-				max *= lenD
-			case 'l': // lowercase
-				max *= lenL
-			case 'w': // lowercase + number
-				max *= lenW
-			case 'u': // uppercase
-				max *= lenU
-			case 'p': // uppercase + number
-				max *= lenP
-			case 'a': // lowercase + uppercase
-				max *= lenA
-			case 'c': // custom
-				max *= lenC
-			}
-		}
-		// limit the answer to maxNumCodes to prevent integer overflow issues
-		if max > maxNumCodes {
-			return maxNumCodes
-		}
+	if cf.policy != nil {
+		return cf.policyMaxCodes()
+	}
+
+	// each code takes the next value of the counter
+	if cf.counted() {
+		return cf.counterSpace()
+	}
+
+	// limit the answer to maxNumCodes to prevent integer overflow issues
+	max := cf.drawSpace(cf.slots())
+	if max > maxNumCodes {
+		return maxNumCodes
+	}
+	return max
+
+}
+
+// drawSpace returns the number of codes that random codes can be kept from,
+// as MaxCodes does for formats without a counter, but limited to
+// math.MaxInt64 instead of maxNumCodes, so that the retry budget of a sparse
+// space reflects its size.
+func (cf *CodeFactory) drawSpace(slots []slot) int64 {
+	max := randomSpace(slots)
+	if max <= 1 {
+		return 0
+	}
+
+	// codes have to be far enough apart, including from the issued codes
+	if cf.minDist > 1 {
+		max = cf.distanceCapacity(slots) - int64(len(cf.issued))
 	}
-	if max == 1 {
+
+	// reserved and blocked codes can't be generated
+	max -= cf.reservedCount() + cf.blockedCount(slots)
+	if max < 0 {
 		return 0
 	}
 	return max
+}
 
+// Entropy returns the number of bits of randomness in each code, which is the
+// base 2 logarithm of the number of ways the random characters and words of
+// the format can be filled.  Fields, signatures, time, counter, date, and
+// parity characters don't add to it.
+func (cf *CodeFactory) Entropy() float64 {
+	if cf.policy != nil {
+		return cf.policyEntropy()
+	}
+	bits := 0.0
+	for _, s := range cf.slots() {
+		if s.random() && len(s.vals) > 0 {
+			bits += math.Log2(float64(len(s.vals)))
+		}
+	}
+	return bits
 }
 
 // Generate generates `num` codes using the settings given in `cf`, and returns
 // the codes as an unordered slice of strings.
 //
 // It will return an error if the number of codes is too hight for the given
-// format and character sets in `cf`, or if `num` is greater than the maximum
-// allowed, which is currently set at 10,000,000 codes.
+// format and character sets in `cf`, if `num` is negative, or if `num` is
+// greater than the maximum allowed, which is currently set at 10,000,000 codes.
 func (cf *CodeFactory) Generate(num int) ([]string, error) {
+	return cf.GenerateContext(context.Background(), num)
+}
+
+// GenerateContext generates `num` codes in the same way as Generate, but stops
+// and returns the context's error if `ctx` is cancelled or its deadline passes
+// before all the codes have been generated.
+func (cf *CodeFactory) GenerateContext(ctx context.Context, num int) ([]string, error) {
+	res, _, err := cf.generate(ctx, num, nil)
+	return res, err
+}
+
+// generate generates the codes along with a report on how they were generated.
+// The report is filled in even if generating the codes fails.  If `sp` isn't
+// nil, the codes are only drawn from that span of the space of codes.
+func (cf *CodeFactory) generate(ctx context.Context, num int, sp *span) ([]string, Report, error) {
+	start := time.Now()
+	rep := Report{
+		Fingerprint: cf.Fingerprint(),
+		Seed:        cf.seed,
+		Seeded:      cf.rnd != nil,
+		Entropy:     cf.Entropy(),
+	}
+	maxCodes := cf.MaxCodes()
+	if sp != nil {
+		maxCodes = sp.maxCodes()
+	}
+	if maxCodes > 0 {
+		rep.Utilization = float64(num) / float64(maxCodes)
+	}
 
 	// get a map of the codes
-	m, err := cf.generateMap(num)
+	m, err := cf.generateMap(ctx, num, &rep, sp)
+	rep.Elapsed = time.Since(start)
 	if err != nil {
-		return []string{}, err
+		return []string{}, rep, err
 	}
 
 	// convert map to string slice
@@ -328,132 +478,178 @@ func (cf *CodeFactory) Generate(num int) ([]string, error) {
 	for k := range m {
 		res = append(res, k)
 	}
-	return res, nil
+	return res, rep, nil
 }
 
-func (cf *CodeFactory) generateMap(num int) (map[string]bool, error) {
+func (cf *CodeFactory) generateMap(ctx context.Context, num int, rep *Report, sp *span) (map[string]bool, error) {
 	res := map[string]bool{}
 
+	if err := ctx.Err(); err != nil {
+		return res, err
+	}
+	if err := cf.check(num); err != nil {
+		return res, err
+	}
+	if cf.policy != nil {
+		return cf.generatePolicy(ctx, num, rep)
+	}
 	maxCodes := cf.MaxCodes()
-	if maxCodes == 0 {
-		return res, errNoCharacters
-	} else if int64(num) > cf.MaxCodes() {
-		return res, errTooManyCodes
-	}
-
-	// strings to build codes from
-	x := cf.num + cf.upper + cf.lower
-	d := cf.num
-	l := cf.lower
-	w := cf.lower + cf.num
-	u := cf.upper
-	p := cf.upper + cf.num
-	a := cf.upper + cf.lower
-	c := cf.custom
-
-	// lengths for optimisation
-	lenX := len(x)
-	lenD := len(d)
-	lenL := len(l)
-	lenW := len(w)
-	lenU := len(u)
-	lenP := len(p)
-	lenA := len(a)
-	lenC := len(c)
-
-	retries := 0
-	maxRetries := (num * maxRetriesPercent / 100) + maxRetriesBase
-
-	for i := 1; i <= num; i++ {
-
-		// result string always starts with a prefix
-		r := cf.prefix
-
-		for _, v := range cf.format {
-			// formatting symbol
-			if !unicode.IsLetter(v) {
-				r += string(v)
-				continue
-			}
-			// is code character
-			switch v {
+	if sp != nil {
+		maxCodes = sp.maxCodes()
+		if int64(num) > maxCodes {
+			return res, &CountError{Requested: int64(num), Achievable: maxCodes}
+		}
+	}
+	signed := cf.signed()
 
-			// any character in sets
-			case 'x':
-				r += string(x[rand.Intn(lenX)])
+	slots := cf.slots()
+	rs, _ := cf.reedSolomon(slots)
+	body, err := cf.newBody(slots)
+	if err != nil {
+		return res, err
+	}
+	counted := cf.counted()
 
-			// any number digit
-			case 'd':
-				r += string(d[rand.Intn(lenD)])
+	rng := cf.rng()
 
-			// any lowercase letter
-			case 'l':
-				r += string(l[rand.Intn(lenL)])
+	// dense requests draw distinct indexes into the space of codes, as most
+	// random codes would be duplicates
+	space := randomSpace(slots)
+	if sp != nil {
+		space = sp.int64()
+	}
+	var smp *sampler
+	if cf.dense(num, space) {
+		smp = newSampler(rng, space)
+	}
+	idx := new(big.Int)
 
-			// any lowercase letter or number
-			case 'w':
-				r += string(w[rand.Intn(lenW)])
+	near := cf.newNear()
 
-			// any uppercase letter
-			case 'u':
-				r += string(u[rand.Intn(lenU)])
+	// the retry budget comes from the whole space, as duplicates are rarer
+	// than maxCodes suggests once the space is larger than maxNumCodes
+	retrySpace := cf.drawSpace(slots)
+	if sp != nil && sp.int64() < retrySpace {
+		retrySpace = sp.int64()
+	}
+	maxRetries := cf.maxRetries(num, retrySpace)
+	rep.RetryBudget = maxRetries
+	start := time.Now()
 
-			// any uppercase letter or number
-			case 'p':
-				r += string(p[rand.Intn(lenP)])
+	for i := 1; i <= num; i++ {
 
-			// any letter (upper or lowercase)
-			case 'a':
-				r += string(a[rand.Intn(lenA)])
+		// check for cancellation before every try, as codes that are rejected
+		// can take as long to draw as those that are kept
+		if err := ctx.Err(); err != nil {
+			return map[string]bool{}, err
+		}
 
-				// any custom character
-			case 'c':
-				r += string(c[rand.Intn(lenC)])
+		switch {
+		case smp != nil:
+			n, ok := smp.next()
+			if !ok {
+				// every index has been drawn, and the rest were rejected
+				return map[string]bool{}, ErrMaxRetriesExceeded
 			}
-
+			if sp != nil {
+				unrankBig(slots, body, idx.Add(sp.lo, big.NewInt(n)))
+			} else {
+				unrank(slots, body, n)
+			}
+		case sp != nil:
+			unrankBig(slots, body, idx.Add(sp.lo, randomBig(rng, sp.size)))
+		default:
+			for j, s := range slots {
+				// code character
+				if s.random() {
+					body[j] = s.vals[rng.Intn(len(s.vals))]
+				}
+			}
+		}
+		if counted {
+			packCounter(slots, body, cf.counter+int64(i-1)*cf.counterStep())
+		}
+		if signed {
+			cf.sign(slots, body)
 		}
-		r += cf.suffix
+		if rs != nil {
+			rs.encode(slots, body)
+		}
+
+		// result string always starts with a prefix and ends with a suffix
+		r := cf.prefix + strings.Join(body, "") + cf.suffix
 
-		// check if r is in res
-		if res[r] == true {
+		// check if r is in res, reserved, too close to a code in res, or blocked
+		var code []rune
+		if near != nil {
+			code = []rune(strings.Join(body, ""))
+		}
+		if res[r] == true || cf.reserved[r] || (near != nil && near.near(code)) || cf.blocked(body) {
 			i-- // generate a new code
-			retries++
-			if retries > maxRetries {
-				return map[string]bool{}, errMaxRetriesExceeded
+			rep.Duplicates++
+			// indexes drawn without replacement are only rejected if reserved
+			// or blocked, so they run until the space is used up
+			if smp == nil && rep.Duplicates > maxRetries {
+				return map[string]bool{}, ErrMaxRetriesExceeded
 			}
 			continue
 		}
 
 		res[r] = true
+		if near != nil {
+			near.add(code)
+		}
+
+		// report progress now and then
+		if i%progressInterval == 0 {
+			cf.report(start, i, num, rep.Duplicates)
+		}
+	}
+	cf.report(start, num, num, rep.Duplicates)
+	if counted {
+		cf.counter += int64(num) * cf.counterStep()
 	}
 	return res, nil
 }
 
-func hasWhitespace(s string) bool {
-	for _, v := range s {
-		if unicode.IsSpace(rune(v)) {
-			return true
-		}
+// check returns an error if `num` codes can't be generated with the current
+// settings.
+func (cf *CodeFactory) check(num int) error {
+	if num < 0 {
+		return ErrInvalidCount
+	}
+	if cf.policy != nil {
+		return cf.checkPolicy(num)
+	}
+	if err := cf.checkFields(); err != nil {
+		return err
+	}
+	if !cf.wordsApart(cf.slots()) {
+		return ErrWordsJoined
 	}
-	return false
-}
 
-func hasDuplicates(s string) bool {
-	for _, v := range s {
-		if strings.Count(s, string(v)) != 1 {
-			return true
-		}
+	maxCodes := cf.MaxCodes()
+	if cf.counted() && randomSpace(cf.slots()) == 0 {
+		return ErrNoCharacters
+	} else if cf.counted() && maxCodes == 0 {
+		return ErrCounterOverflow
+	} else if maxCodes == 0 && randomSpace(cf.slots()) <= 1 {
+		return ErrNoCharacters
+	} else if int64(num) > maxCodes {
+		return &CountError{Requested: int64(num), Achievable: maxCodes}
 	}
-	return false
-}
 
-func allLatin1(s string) bool {
-	for _, v := range s {
-		if v > unicode.MaxLatin1 {
-			return false
-		}
+	if cf.signed() && cf.key == nil {
+		return ErrNoKey
+	} else if cf.signed() && cf.set('s') == "" {
+		return ErrNoCharacters
+	} else if cf.timed() && cf.set('t') == "" {
+		return ErrNoCharacters
 	}
-	return true
+	if _, err := cf.reedSolomon(cf.slots()); err != nil {
+		return err
+	}
+	return nil
 }
 
 func isIncludedIn(s string, v rune) bool {
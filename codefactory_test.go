@@ -1,6 +1,7 @@
 package codefactory
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -125,13 +126,13 @@ func TestSetCustom(t *testing.T) {
 			desc:       "input has whitespace",
 			input:      " bc3 46NñŒ",
 			wantCustom: "",
-			wantErr:    errWhitespace,
+			wantErr:    ErrWhitespace,
 		},
 		{
 			desc:       "has duplicates",
 			input:      "bctevb32",
 			wantCustom: "",
-			wantErr:    errDuplicates,
+			wantErr:    ErrDuplicates,
 		},
 		{
 			desc:       "successive sets",
@@ -156,7 +157,7 @@ func TestSetCustom(t *testing.T) {
 			So(cf.upper, ShouldResemble, defaultUppercase)
 			So(cf.lower, ShouldResemble, defaultLowercase)
 			So(cf.num, ShouldResemble, defaultNumbers)
-			So(err, ShouldEqual, tt.wantErr)
+			So(errors.Is(err, tt.wantErr), ShouldBeTrue)
 		})
 	}
 }
@@ -179,13 +180,13 @@ func TestSetFormat(t *testing.T) {
 			desc:       "input has inivalid whitespace",
 			input:      "\t\n #xxxx",
 			wantFormat: defaultFormat,
-			wantErr:    errInvalidFormat,
+			wantErr:    ErrInvalidFormat,
 		},
 		{
 			desc:       "has numbers",
 			input:      "#xxx2a",
 			wantFormat: defaultFormat,
-			wantErr:    errInvalidFormat,
+			wantErr:    ErrInvalidFormat,
 		},
 		{
 			desc:       "successive sets",
@@ -198,13 +199,13 @@ func TestSetFormat(t *testing.T) {
 			desc:       "invalid letters used",
 			input:      "#afaa",
 			wantFormat: defaultFormat,
-			wantErr:    errInvalidFormat,
+			wantErr:    ErrInvalidFormat,
 		},
 		{
 			desc:       "uppercase letters used",
 			input:      "#aAaa",
 			wantFormat: defaultFormat,
-			wantErr:    errInvalidFormat,
+			wantErr:    ErrInvalidFormat,
 		},
 	}
 
@@ -223,7 +224,7 @@ func TestSetFormat(t *testing.T) {
 			So(cf.lower, ShouldResemble, defaultLowercase)
 			So(cf.custom, ShouldResemble, "")
 			So(cf.num, ShouldResemble, defaultNumbers)
-			So(err, ShouldEqual, tt.wantErr)
+			So(errors.Is(err, tt.wantErr), ShouldBeTrue)
 		})
 	}
 }
@@ -249,42 +250,42 @@ func TestExtendLetters(t *testing.T) {
 			input:     "ÀÀ",
 			wantLower: defaultLowercase,
 			wantUpper: defaultUppercase,
-			wantErr:   errDuplicates,
+			wantErr:   ErrDuplicates,
 		},
 		{
 			desc:      "non Latin1 input",
 			input:     "ÀÁÂ您好",
 			wantLower: defaultLowercase,
 			wantUpper: defaultUppercase,
-			wantErr:   errNotLatin1,
+			wantErr:   ErrNotLatin1,
 		},
 		{
 			desc:      "input with whitespace",
 			input:     "À Á",
 			wantLower: defaultLowercase,
 			wantUpper: defaultUppercase,
-			wantErr:   errWhitespace,
+			wantErr:   ErrWhitespace,
 		},
 		{
 			desc:      "uppercase already exists",
 			input:     "ÀD",
 			wantLower: defaultLowercase,
 			wantUpper: defaultUppercase,
-			wantErr:   errAlreadyExist,
+			wantErr:   ErrAlreadyExist,
 		},
 		{
 			desc:      "lowercase already exists",
 			input:     "ñc",
 			wantLower: defaultLowercase,
 			wantUpper: defaultUppercase,
-			wantErr:   errAlreadyExist,
+			wantErr:   ErrAlreadyExist,
 		},
 		{
 			desc:      "non-letter input",
 			input:     "ñ9",
 			wantLower: defaultLowercase,
 			wantUpper: defaultUppercase,
-			wantErr:   errNotLetter,
+			wantErr:   ErrNotLetter,
 		},
 		{
 			desc:      "consecutive extends",
@@ -311,7 +312,7 @@ func TestExtendLetters(t *testing.T) {
 			So(cf.lower, ShouldResemble, tt.wantLower)
 			So(cf.custom, ShouldResemble, "")
 			So(cf.num, ShouldResemble, defaultNumbers)
-			So(err, ShouldEqual, tt.wantErr)
+			So(errors.Is(err, tt.wantErr), ShouldBeTrue)
 		})
 	}
 }
@@ -339,7 +340,7 @@ func TestSetPrefix(t *testing.T) {
 			desc:       "leading whitespace",
 			input:      " #Code: ",
 			wantPrefix: "pre",
-			wantErr:    errLeadingWhitespace,
+			wantErr:    ErrLeadingWhitespace,
 		},
 	}
 
@@ -380,7 +381,7 @@ func TestSetSuffix(t *testing.T) {
 			desc:       "trailing whitespace",
 			input:      "end ",
 			wantSuffix: "suf",
-			wantErr:    errTrailingWhitespace,
+			wantErr:    ErrTrailingWhitespace,
 		},
 	}
 
@@ -573,14 +574,29 @@ func TestGenerate(t *testing.T) {
 
 		cf := New()
 		cf.SetFormat("$ dd")
-		numcodes := 90
+		cf.SetRetryPolicy(FixedPolicy{})
+		cf.SetSeed(1)
+		numcodes := 40
 
 		res, err := cf.Generate(numcodes)
 
-		So(err, ShouldEqual, errMaxRetriesExceeded)
+		So(err, ShouldEqual, ErrMaxRetriesExceeded)
 		So(len(res), ShouldEqual, 0)
 	})
 
+	Convey("testing with every possible code", t, func() {
+
+		cf := New()
+		cf.SetFormat("$ dd")
+		cf.SetRetryPolicy(FixedPolicy{})
+		numcodes := 100
+
+		res, err := cf.Generate(numcodes)
+
+		So(err, ShouldBeNil)
+		So(len(res), ShouldEqual, numcodes)
+	})
+
 	Convey("testing with a too many codes requested", t, func() {
 
 		cf := New()
@@ -589,7 +605,7 @@ func TestGenerate(t *testing.T) {
 
 		res, err := cf.Generate(numcodes)
 
-		So(err, ShouldEqual, errTooManyCodes)
+		So(errors.Is(err, ErrTooManyCodes), ShouldBeTrue)
 		So(len(res), ShouldEqual, 0)
 	})
 
@@ -620,9 +636,25 @@ func TestGenerate(t *testing.T) {
 
 		res, err := cf.Generate(1)
 
-		So(err, ShouldEqual, errNoCharacters)
+		So(err, ShouldEqual, ErrNoCharacters)
+		So(res, ShouldResemble, []string{})
+
+	})
+
+	Convey("testing with a negative number of codes", t, func() {
+
+		cf := New()
+		cf.SetFormat("dd")
+
+		res, err := cf.Generate(-1)
+
+		So(err, ShouldEqual, ErrInvalidCount)
 		So(res, ShouldResemble, []string{})
 
+		res, err = cf.Generate(0)
+
+		So(err, ShouldBeNil)
+		So(res, ShouldResemble, []string{})
 	})
 }
 
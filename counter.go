@@ -0,0 +1,111 @@
+package codefactory
+
+import (
+	"fmt"
+	"time"
+)
+
+// dateVerbs are the format characters that hold part of the date.
+const dateVerbs = "ymeig"
+
+// SetCounter sets the next value of the counter held in the counter characters
+// ('n') of the format, and the step it increases by for each code.  The
+// counter starts at 0 with a step of 1.
+//
+// Each code of a batch takes the next value of the counter, so codes with a
+// counter are unique even if they have few or no random characters.  Store the
+// value of Counter after each batch and pass it to SetCounter to carry on from
+// there in a later run.
+func (cf *CodeFactory) SetCounter(start, step int64) error {
+	if start < 0 || step < 1 {
+		return ErrCounterRange
+	}
+	cf.counter = start
+	cf.step = step
+	return nil
+}
+
+// Counter returns the value of the counter that the next code will take.
+func (cf *CodeFactory) Counter() int64 {
+	return cf.counter
+}
+
+// counted reports whether the format contains counter characters.
+func (cf *CodeFactory) counted() bool {
+	return cf.hasVerb('n')
+}
+
+func (cf *CodeFactory) counterStep() int64 {
+	if cf.step == 0 {
+		return 1
+	}
+	return cf.step
+}
+
+// counterSpace returns the number of counter values left before the counter
+// no longer fits in the counter characters, limited to maxNumCodes.
+func (cf *CodeFactory) counterSpace() int64 {
+	capacity := int64(1)
+	for _, s := range cf.slots() {
+		if s.verb == 'n' {
+			capacity = mulCapped(capacity, 10)
+		}
+	}
+	if cf.counter >= capacity {
+		return 0
+	}
+	left := (capacity-1-cf.counter)/cf.counterStep() + 1
+	if left > maxNumCodes {
+		return maxNumCodes
+	}
+	return left
+}
+
+// packCounter fills the counter slots of body with the value v.
+func packCounter(slots []slot, body []string, v int64) {
+	for j := len(slots) - 1; j >= 0; j-- {
+		if slots[j].verb != 'n' {
+			continue
+		}
+		body[j] = slots[j].vals[v%10]
+		v /= 10
+	}
+}
+
+// packDate fills each run of date slots of body with the zero-padded part of
+// the date of `t`, keeping as many of the trailing digits as there are slots.
+func packDate(slots []slot, body []string, t time.Time) {
+	isoYear, isoWeek := t.ISOWeek()
+	for j := 0; j < len(slots); {
+		v := slots[j].verb
+		if !isIncludedIn(dateVerbs, v) {
+			j++
+			continue
+		}
+
+		k := j
+		for k < len(slots) && slots[k].verb == v {
+			k++
+		}
+
+		var part int
+		switch v {
+		case 'y':
+			part = t.Year()
+		case 'm':
+			part = int(t.Month())
+		case 'e':
+			part = t.Day()
+		case 'i':
+			part = isoWeek
+		case 'g':
+			part = isoYear
+		}
+		s := fmt.Sprintf("%0*d", k-j, part)
+		s = s[len(s)-(k-j):]
+		for i := j; i < k; i++ {
+			body[i] = s[i-j : i-j+1]
+		}
+		j = k
+	}
+}
@@ -0,0 +1,144 @@
+package codefactory
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCounter(t *testing.T) {
+	var testCases = []struct {
+		desc  string
+		start int64
+		step  int64
+		num   int
+		want  []string
+		next  int64
+	}{
+		{
+			desc:  "count from a start value",
+			start: 123,
+			step:  1,
+			num:   3,
+			want:  []string{"INV-000123", "INV-000124", "INV-000125"},
+			next:  126,
+		},
+		{
+			desc:  "count in steps",
+			start: 0,
+			step:  5,
+			num:   3,
+			want:  []string{"INV-000000", "INV-000005", "INV-000010"},
+			next:  15,
+		},
+	}
+
+	for i, tt := range testCases {
+		Convey(fmt.Sprintf("Case # %d: %s", i, tt.desc), t, func() {
+
+			cf := New()
+			cf.SetPrefix("INV-")
+			cf.SetFormat("nnnnnn")
+			So(cf.SetCounter(tt.start, tt.step), ShouldBeNil)
+
+			res, err := cf.Generate(tt.num)
+			So(err, ShouldBeNil)
+			sort.Strings(res)
+			So(res, ShouldResemble, tt.want)
+			So(cf.Counter(), ShouldEqual, tt.next)
+			So(cf.Validate(res[0]), ShouldBeNil)
+		})
+	}
+
+	Convey("Codes with a counter are unique without random characters", t, func() {
+
+		cf := New()
+		cf.SetFormat("nnn-d")
+		So(cf.MaxCodes(), ShouldEqual, 1000)
+		So(cf.Feasible(1000), ShouldBeNil)
+
+		res, err := cf.Generate(1000)
+		So(err, ShouldBeNil)
+		So(len(res), ShouldEqual, 1000)
+		So(cf.MaxCodes(), ShouldEqual, 0)
+
+		_, err = cf.Generate(1)
+		So(err, ShouldEqual, ErrCounterOverflow)
+	})
+
+	Convey("The counter can't run past its characters", t, func() {
+
+		cf := New()
+		cf.SetFormat("nn")
+		cf.SetCounter(90, 2)
+		So(cf.MaxCodes(), ShouldEqual, 5)
+
+		_, err := cf.Generate(6)
+		So(err, ShouldResemble, &CountError{Requested: 6, Achievable: 5})
+		So(cf.Counter(), ShouldEqual, 90)
+	})
+
+	Convey("Invalid counters are rejected", t, func() {
+
+		cf := New()
+		So(cf.SetCounter(-1, 1), ShouldEqual, ErrCounterRange)
+		So(cf.SetCounter(0, 0), ShouldEqual, ErrCounterRange)
+	})
+}
+
+func TestDate(t *testing.T) {
+	var testCases = []struct {
+		desc   string
+		format string
+		now    time.Time
+		want   string
+	}{
+		{
+			desc:   "year and month",
+			format: "yymm",
+			now:    time.Date(2026, 10, 5, 12, 0, 0, 0, time.UTC),
+			want:   "2610",
+		},
+		{
+			desc:   "full date",
+			format: "yyyy-mm-ee",
+			now:    time.Date(2026, 3, 9, 12, 0, 0, 0, time.UTC),
+			want:   "2026-03-09",
+		},
+		{
+			desc:   "ISO week in the previous year",
+			format: `gggg-\W-ii`,
+			now:    time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC),
+			want:   "2020-W-53",
+		},
+	}
+
+	for i, tt := range testCases {
+		Convey(fmt.Sprintf("Case # %d: %s", i, tt.desc), t, func() {
+
+			cf := New()
+			cf.SetEscape(backslash)
+			So(cf.SetFormat(tt.format+"-nnn"), ShouldBeNil)
+			cf.now = func() time.Time { return tt.now }
+
+			res, err := cf.Generate(2)
+			So(err, ShouldBeNil)
+			sort.Strings(res)
+			So(res, ShouldResemble, []string{tt.want + "-000", tt.want + "-001"})
+		})
+	}
+
+	Convey("Date characters aren't random", t, func() {
+
+		cf := New()
+		cf.SetFormat("yymmee-xx")
+		So(cf.MaxCodes(), ShouldEqual, 62*62)
+
+		got, err := cf.Normalize("261005 ab")
+		So(err, ShouldBeNil)
+		So(got, ShouldEqual, "261005-ab")
+	})
+}
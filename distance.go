@@ -0,0 +1,333 @@
+package codefactory
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Metric is a measure of how far apart two codes are.
+type Metric int
+
+const (
+	// Hamming counts the positions at which two codes differ.
+	Hamming Metric = iota
+
+	// DamerauLevenshtein counts the insertions, deletions, substitutions, and
+	// transpositions of characters needed to turn one code into the other.
+	DamerauLevenshtein
+)
+
+// Distance returns the distance between the codes a and b.
+func (m Metric) Distance(a, b string) int {
+	return m.distance([]rune(a), []rune(b))
+}
+
+func (m Metric) distance(a, b []rune) int {
+	if m == DamerauLevenshtein {
+		return damerauLevenshtein(a, b)
+	}
+	return hamming(a, b)
+}
+
+// SetMinDistance makes every pair of codes in a batch at least `k` apart, as
+// measured by the metric `m`, so that a code with fewer than `k` typos can't
+// be mistaken for another one.  Codes closer than that to one already
+// generated are discarded like duplicates.
+//
+// Codes issued earlier, such as those of previous batches kept in a database,
+// can be given as `issued`, in their canonical form with the prefix and
+// suffix, so that new codes are kept `k` apart from them as well.  Each call
+// replaces the issued codes of the last one.
+//
+// While the minimum distance is more than 1, MaxCodes returns an estimate of
+// how many codes fit, from the Gilbert-Varshamov bound, less the issued codes,
+// and requests are never drawn without replacement.
+func (cf *CodeFactory) SetMinDistance(k int, m Metric, issued ...string) error {
+	if k < 1 || (m != Hamming && m != DamerauLevenshtein) {
+		return ErrInvalidDistance
+	}
+	cf.minDist = k
+	cf.metric = m
+	cf.issued = nil
+	for _, c := range issued {
+		c = strings.TrimSuffix(strings.TrimPrefix(c, cf.prefix), cf.suffix)
+		cf.issued = append(cf.issued, c)
+	}
+	sort.Strings(cf.issued)
+	return nil
+}
+
+// newNear returns an index of the issued codes, which new codes are added to
+// as they are generated, or nil if there is no minimum distance.
+func (cf *CodeFactory) newNear() *nearIndex {
+	if cf.minDist <= 1 {
+		return nil
+	}
+	ni := newNearIndex(cf.minDist, cf.metric)
+	for _, c := range cf.issued {
+		ni.add([]rune(c))
+	}
+	return ni
+}
+
+// distanceCapacity estimates how many codes fit in the space of the random
+// slots while being at least cf.minDist apart.  This is the size of the space
+// divided by the number of codes closer than cf.minDist to any one code,
+// limited to math.MaxInt64.
+func (cf *CodeFactory) distanceCapacity(slots []slot) int64 {
+	r := cf.minDist - 1
+
+	// the coefficients of the product of (1 + (q-1)z) over the random slots
+	// count the codes at each Hamming distance
+	ball := make([]float64, r+1)
+	ball[0] = 1
+	space := 1.0
+	n := 0
+	for _, s := range slots {
+		if !s.random() {
+			continue
+		}
+		q := float64(len(s.vals))
+		for d := r; d >= 1; d-- {
+			ball[d] += ball[d-1] * (q - 1)
+		}
+		space *= q
+		n++
+	}
+
+	// each transposition of neighbouring characters adds one more code
+	if cf.metric == DamerauLevenshtein {
+		for t := 0; t < n-1; t++ {
+			for d := r; d >= 1; d-- {
+				ball[d] += ball[d-1]
+			}
+		}
+	}
+
+	vol := 0.0
+	for _, v := range ball {
+		vol += v
+	}
+	capacity := space / vol
+	if capacity >= math.MaxInt64 {
+		return math.MaxInt64
+	} else if capacity < 1 {
+		return 1
+	}
+	return int64(capacity)
+}
+
+// nearIndex finds codes closer than k to each other by splitting them into
+// blocks.  Codes with a Hamming distance of less than k have one of k blocks
+// in common.  Each Damerau-Levenshtein edit changes at most two neighbouring
+// blocks and shifts the rest by at most one, so codes closer than k have one
+// of 2k-1 blocks in common, shifted by less than k.
+type nearIndex struct {
+	k      int
+	metric Metric
+	shift  int
+	n      int      // length of the codes that the blocks are laid out for
+	blocks [][2]int // start and end of each block, or nil to scan every code
+	index  map[string][]int
+	codes  [][]rune
+}
+
+func newNearIndex(k int, m Metric) *nearIndex {
+	return &nearIndex{k: k, metric: m, index: map[string][]int{}}
+}
+
+// near reports whether code is closer than k to any code in the index.
+func (ni *nearIndex) near(code []rune) bool {
+	found := false
+	ni.candidates(code, func(c int) bool {
+		found = ni.metric.distance(code, ni.codes[c]) < ni.k
+		return !found
+	})
+	return found
+}
+
+// candidates calls f with the position in the index of each code that shares
+// a block with code, and so may be closer than k to it, until f returns
+// false.  As the blocks are laid out on the codes in the index, code itself
+// may be of any length.
+func (ni *nearIndex) candidates(code []rune, f func(int) bool) {
+	if ni.blocks == nil {
+		for c := range ni.codes {
+			if !f(c) {
+				return
+			}
+		}
+		return
+	}
+
+	checked := map[int]bool{}
+	for j, b := range ni.blocks {
+		for s := -ni.shift; s <= ni.shift; s++ {
+			lo, hi := b[0]+s, b[1]+s
+			if lo < 0 || hi > len(code) {
+				continue
+			}
+			for _, c := range ni.index[blockKey(j, code[lo:hi])] {
+				if checked[c] {
+					continue
+				}
+				checked[c] = true
+				if !f(c) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// add adds code to the index.  The blocks are laid out for the length of the
+// first code added, as codes of a format without words have the same length.
+// Once a code of another length is added, every code is scanned instead.
+func (ni *nearIndex) add(code []rune) {
+	if len(ni.codes) == 0 {
+		ni.layout(len(code))
+	} else if len(code) != ni.n {
+		ni.blocks = nil
+	}
+	id := len(ni.codes)
+	ni.codes = append(ni.codes, code)
+	for j, b := range ni.blocks {
+		if b[1] > len(code) {
+			continue
+		}
+		key := blockKey(j, code[b[0]:b[1]])
+		ni.index[key] = append(ni.index[key], id)
+	}
+}
+
+// layout splits codes of length n into blocks.  Codes too short to give each
+// block a character are scanned one by one instead.
+func (ni *nearIndex) layout(n int) {
+	ni.n = n
+	num := ni.k
+	if ni.metric == DamerauLevenshtein {
+		num = 2*ni.k - 1
+		ni.shift = ni.k - 1
+	}
+	if num > n {
+		return
+	}
+	for i := 0; i < num; i++ {
+		ni.blocks = append(ni.blocks, [2]int{i * n / num, (i + 1) * n / num})
+	}
+}
+
+func blockKey(j int, block []rune) string {
+	return strconv.Itoa(j) + ":" + string(block)
+}
+
+// hamming returns the number of positions at which a and b differ, counting
+// any extra characters in the longer of the two.
+func hamming(a, b []rune) int {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	d := len(b) - len(a)
+	for i := range a {
+		if a[i] != b[i] {
+			d++
+		}
+	}
+	return d
+}
+
+// damerauLevenshtein returns the unrestricted Damerau-Levenshtein distance
+// between a and b, using the algorithm of Lowrance and Wagner.
+func damerauLevenshtein(a, b []rune) int {
+	inf := len(a) + len(b)
+	d := make([][]int, len(a)+2)
+	for i := range d {
+		d[i] = make([]int, len(b)+2)
+	}
+	d[0][0] = inf
+	for i := 0; i <= len(a); i++ {
+		d[i+1][0] = inf
+		d[i+1][1] = i
+	}
+	for j := 0; j <= len(b); j++ {
+		d[0][j+1] = inf
+		d[1][j+1] = j
+	}
+
+	// the last row in which each character was seen in a
+	last := map[rune]int{}
+	for i := 1; i <= len(a); i++ {
+		// the last column in this row in which a matched b
+		match := 0
+		for j := 1; j <= len(b); j++ {
+			k, l := last[b[j-1]], match
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+				match = j
+			}
+			d[i+1][j+1] = minInt(
+				d[i][j]+cost,              // substitution
+				d[i+1][j]+1,               // insertion
+				d[i][j+1]+1,               // deletion
+				d[k][l]+(i-k-1)+1+(j-l-1), // transposition
+			)
+		}
+		last[a[i-1]] = i
+	}
+	return d[len(a)+1][len(b)+1]
+}
+
+// editBound returns a lower bound on the Damerau-Levenshtein distance between
+// a and b, from the number of times each character appears in them.  Each
+// substitution changes two of the counts by one, each insertion or deletion
+// changes one, and transpositions don't change any.
+func editBound(a, b []rune) int {
+	var latin [256]int
+	var other map[rune]int
+	count := func(r rune, n int) {
+		if r < 256 {
+			latin[r] += n
+			return
+		}
+		if other == nil {
+			other = map[rune]int{}
+		}
+		other[r] += n
+	}
+	for _, r := range a {
+		count(r, 1)
+	}
+	for _, r := range b {
+		count(r, -1)
+	}
+
+	diff := 0
+	for _, runes := range [][]rune{a, b} {
+		for _, r := range runes {
+			n := 0
+			if r < 256 {
+				n, latin[r] = latin[r], 0
+			} else {
+				n, other[r] = other[r], 0
+			}
+			if n < 0 {
+				n = -n
+			}
+			diff += n
+		}
+	}
+	return (diff + 1) / 2
+}
+
+func minInt(v ...int) int {
+	m := v[0]
+	for _, n := range v[1:] {
+		if n < m {
+			m = n
+		}
+	}
+	return m
+}
@@ -0,0 +1,212 @@
+package codefactory
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDistance(t *testing.T) {
+	var testCases = []struct {
+		desc   string
+		a, b   string
+		wantH  int
+		wantDL int
+	}{
+		{
+			desc:   "same code",
+			a:      "ab12",
+			b:      "ab12",
+			wantH:  0,
+			wantDL: 0,
+		},
+		{
+			desc:   "one typo",
+			a:      "ab12",
+			b:      "ab13",
+			wantH:  1,
+			wantDL: 1,
+		},
+		{
+			desc:   "swapped characters",
+			a:      "ab12",
+			b:      "ab21",
+			wantH:  2,
+			wantDL: 1,
+		},
+		{
+			desc:   "missed character",
+			a:      "ab12",
+			b:      "b12",
+			wantH:  4,
+			wantDL: 1,
+		},
+		{
+			desc:   "transposition with an insertion",
+			a:      "ca",
+			b:      "abc",
+			wantH:  3,
+			wantDL: 2,
+		},
+		{
+			desc:   "kitten and sitting",
+			a:      "kitten",
+			b:      "sitting",
+			wantH:  3,
+			wantDL: 3,
+		},
+	}
+
+	for i, tt := range testCases {
+		Convey(fmt.Sprintf("Case # %d: %s", i, tt.desc), t, func() {
+			So(Hamming.Distance(tt.a, tt.b), ShouldEqual, tt.wantH)
+			So(DamerauLevenshtein.Distance(tt.a, tt.b), ShouldEqual, tt.wantDL)
+			So(DamerauLevenshtein.Distance(tt.b, tt.a), ShouldEqual, tt.wantDL)
+		})
+	}
+}
+
+func TestEditBound(t *testing.T) {
+
+	Convey("The bound never exceeds the distance", t, func() {
+
+		rng := rand.New(rand.NewSource(1))
+		chars := []rune("abcé€")
+		random := func() []rune {
+			res := []rune{}
+			for j := rng.Intn(7); j > 0; j-- {
+				res = append(res, chars[rng.Intn(len(chars))])
+			}
+			return res
+		}
+		for n := 0; n < 5000; n++ {
+			a, b := random(), random()
+			So(editBound(a, b), ShouldBeLessThanOrEqualTo, damerauLevenshtein(a, b))
+		}
+		So(editBound([]rune("abc€"), []rune("xbc€")), ShouldEqual, 1)
+		So(editBound([]rune("abcd"), []rune("badc")), ShouldEqual, 0)
+	})
+}
+
+func TestMinDistance(t *testing.T) {
+	var testCases = []struct {
+		desc     string
+		format   string
+		k        int
+		metric   Metric
+		num      int
+		maxCodes int64
+	}{
+		{
+			desc:     "Hamming distance of 2",
+			format:   "dddd",
+			k:        2,
+			metric:   Hamming,
+			num:      100,
+			maxCodes: 10000 / 37,
+		},
+		{
+			desc:     "Damerau-Levenshtein distance of 2",
+			format:   "dddd",
+			k:        2,
+			metric:   DamerauLevenshtein,
+			num:      100,
+			maxCodes: 10000 / 40,
+		},
+		{
+			desc:     "Hamming distance of 3 with literals",
+			format:   "ww-ww-ww",
+			k:        3,
+			metric:   Hamming,
+			num:      300,
+			maxCodes: 2176782336 / 18586,
+		},
+		{
+			desc:     "Damerau-Levenshtein distance of 3",
+			format:   "pppppp",
+			k:        3,
+			metric:   DamerauLevenshtein,
+			num:      300,
+			maxCodes: 2176782336 / 19651,
+		},
+		{
+			desc:     "codes too short for blocks",
+			format:   "uuu",
+			k:        3,
+			metric:   DamerauLevenshtein,
+			num:      5,
+			maxCodes: 17576 / 2104,
+		},
+	}
+
+	for i, tt := range testCases {
+		Convey(fmt.Sprintf("Case # %d: %s", i, tt.desc), t, func() {
+
+			cf := New()
+			cf.SetFormat(tt.format)
+			cf.SetSeed(1)
+			So(cf.SetMinDistance(tt.k, tt.metric), ShouldBeNil)
+			So(cf.MaxCodes(), ShouldEqual, tt.maxCodes)
+
+			res, err := cf.Generate(tt.num)
+			So(err, ShouldBeNil)
+			So(len(res), ShouldEqual, tt.num)
+
+			far := true
+			for a := range res {
+				for b := a + 1; b < len(res); b++ {
+					if tt.metric.Distance(res[a], res[b]) < tt.k {
+						far = false
+					}
+				}
+			}
+			So(far, ShouldBeTrue)
+		})
+	}
+
+	Convey("Reserved codes are taken off the estimate", t, func() {
+
+		cf := New()
+		cf.SetFormat("uuu")
+		cf.SetMinDistance(3, DamerauLevenshtein)
+		cf.SetReserved([]string{"ABC", "XYZ", "abc"})
+		So(cf.MaxCodes(), ShouldEqual, 17576/2104-2)
+	})
+
+	Convey("New codes are kept apart from issued codes", t, func() {
+
+		cf := New()
+		cf.SetPrefix("#")
+		cf.SetFormat("ddddd")
+		cf.SetSeed(1)
+		So(cf.SetMinDistance(3, Hamming), ShouldBeNil)
+		first, err := cf.Generate(40)
+		So(err, ShouldBeNil)
+		fp := cf.Fingerprint()
+
+		So(cf.SetMinDistance(3, Hamming, first...), ShouldBeNil)
+		So(cf.MaxCodes(), ShouldEqual, 100000/856-40)
+		So(cf.Fingerprint(), ShouldNotEqual, fp)
+		second, err := cf.Generate(40)
+		So(err, ShouldBeNil)
+
+		far := true
+		for _, a := range first {
+			for _, b := range second {
+				if Hamming.Distance(a, b) < 3 {
+					far = false
+				}
+			}
+		}
+		So(far, ShouldBeTrue)
+	})
+
+	Convey("Invalid minimum distances are rejected", t, func() {
+
+		cf := New()
+		So(cf.SetMinDistance(0, Hamming), ShouldEqual, ErrInvalidDistance)
+		So(cf.SetMinDistance(2, Metric(5)), ShouldEqual, ErrInvalidDistance)
+	})
+}
@@ -0,0 +1,132 @@
+package codefactory
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"math/big"
+	"strings"
+)
+
+// feistelRounds is the number of rounds of the Feistel network that shuffles
+// IDs over the space of codes.
+const feistelRounds = 8
+
+// SetSalt sets the secret salt used by EncodeID and DecodeID.  IDs encoded
+// with one salt can't be decoded, or guessed from each other, without it, so
+// EncodeID and DecodeID return ErrNoSalt until a salt is set.
+func (cf *CodeFactory) SetSalt(salt []byte) {
+	cf.salt = append([]byte{}, salt...)
+}
+
+// EncodeID encodes a non-negative integer, such as a database ID, as a code
+// that looks random under the format and sets of the CodeFactory.  Different
+// IDs always give different codes, and DecodeID turns the code back into the
+// ID.
+//
+// The ID is shuffled over the whole space of codes with a keyed Feistel
+// network, so it must be smaller than the number of possible codes.  Fields,
+// times, dates and the counter are filled in as they would be by Generate,
+// and the code is signed if the format contains signature characters.
+// ErrPolicySet is returned while a policy is set.
+func (cf *CodeFactory) EncodeID(id int64) (string, error) {
+	if cf.policy != nil {
+		return "", ErrPolicySet
+	}
+	if len(cf.salt) == 0 {
+		return "", ErrNoSalt
+	}
+	if err := cf.check(0); err != nil {
+		return "", err
+	}
+	slots := cf.slots()
+	space := spaceBig(slots)
+	if id < 0 || big.NewInt(id).Cmp(space) >= 0 {
+		return "", ErrIDRange
+	}
+
+	body, err := cf.newBody(slots)
+	if err != nil {
+		return "", err
+	}
+	packCounter(slots, body, cf.counter)
+	unrankBig(slots, body, cf.permute(big.NewInt(id), space, false))
+	if cf.signed() {
+		cf.sign(slots, body)
+	}
+	if rs, _ := cf.reedSolomon(slots); rs != nil {
+		rs.encode(slots, body)
+	}
+	return cf.prefix + strings.Join(body, "") + cf.suffix, nil
+}
+
+// DecodeID returns the ID encoded in a canonical code by EncodeID.  If the
+// format contains signature characters, the signature is checked first.
+// ErrPolicySet is returned while a policy is set.
+func (cf *CodeFactory) DecodeID(code string) (int64, error) {
+	if cf.policy != nil {
+		return 0, ErrPolicySet
+	}
+	if len(cf.salt) == 0 {
+		return 0, ErrNoSalt
+	}
+	slots, body, err := cf.parse(code)
+	if err != nil {
+		return 0, err
+	}
+	if cf.signed() {
+		if err := cf.checkSignature(slots, body); err != nil {
+			return 0, err
+		}
+	}
+	id := cf.permute(rankBig(slots, body), spaceBig(slots), true)
+	if !id.IsInt64() {
+		return 0, ErrInvalidCode
+	}
+	return id.Int64(), nil
+}
+
+// permute maps x in [0, space) onto [0, space) with a Feistel network over the
+// smallest even number of bits that holds the space.  Values that fall outside
+// the space are passed through the network again until they are inside it,
+// which keeps the mapping a permutation of the space.
+func (cf *CodeFactory) permute(x, space *big.Int, inverse bool) *big.Int {
+	bits := new(big.Int).Sub(space, big.NewInt(1)).BitLen()
+	if bits == 0 {
+		return x
+	}
+	half := (bits + 1) / 2
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(half)), big.NewInt(1))
+
+	x = new(big.Int).Set(x)
+	for {
+		for r := 0; r < feistelRounds; r++ {
+			left, right := new(big.Int).Rsh(x, uint(half)), new(big.Int).And(x, mask)
+			if inverse {
+				// undo the rounds in reverse order
+				right.Xor(right, cf.round(feistelRounds-1-r, left, half))
+				x.Lsh(right, uint(half)).Or(x, left)
+			} else {
+				left.Xor(left, cf.round(r, right, half))
+				x.Lsh(right, uint(half)).Or(x, left)
+			}
+		}
+		if x.Cmp(space) < 0 {
+			return x
+		}
+	}
+}
+
+// round returns `bits` bits of the HMAC of the round number and half a value.
+func (cf *CodeFactory) round(r int, half *big.Int, bits int) *big.Int {
+	res := new(big.Int)
+	in := half.Bytes()
+	for block := 0; block*sha256.Size*8 < bits; block++ {
+		mac := hmac.New(sha256.New, cf.salt)
+		mac.Write([]byte{byte(r), byte(block)})
+		mac.Write(in)
+		res.Lsh(res, sha256.Size*8)
+		res.Or(res, new(big.Int).SetBytes(mac.Sum(nil)))
+	}
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(bits)), big.NewInt(1))
+	return res.And(res, mask)
+}
@@ -0,0 +1,122 @@
+package codefactory
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestEncodeID(t *testing.T) {
+	var testCases = []struct {
+		desc   string
+		cf     *CodeFactory
+		format string
+		ids    []int64
+	}{
+		{
+			desc:   "small space",
+			cf:     New(),
+			format: "ddd",
+			ids:    []int64{0, 1, 42, 99},
+		},
+		{
+			desc:   "readable order IDs",
+			cf:     NewCrockford(),
+			format: "xxxx-xxxxx",
+			ids:    []int64{0, 1, 2, 123456, 1<<40 - 1},
+		},
+		{
+			desc:   "space larger than int64",
+			cf:     New(),
+			format: "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx",
+			ids:    []int64{0, 1, 1<<63 - 1},
+		},
+		{
+			desc:   "signed codes",
+			cf:     New(),
+			format: "ddddd-ss",
+			ids:    []int64{0, 7, 999},
+		},
+	}
+
+	for i, tt := range testCases {
+		Convey(fmt.Sprintf("Case # %d: %s", i, tt.desc), t, func() {
+
+			cf := tt.cf
+			cf.SetFormat(tt.format)
+			cf.SetSalt([]byte("pepper"))
+			cf.SetKey([]byte("secret"))
+
+			// the field takes the leading code character
+			cf.AddField("shop", 9)
+			cf.SetField("shop", 3)
+
+			seen := map[string]bool{}
+			for _, id := range tt.ids {
+				code, err := cf.EncodeID(id)
+				So(err, ShouldBeNil)
+				So(cf.Validate(code), ShouldBeNil)
+				So(seen[code], ShouldBeFalse)
+				seen[code] = true
+
+				got, err := cf.DecodeID(code)
+				So(err, ShouldBeNil)
+				So(got, ShouldEqual, id)
+			}
+		})
+	}
+
+	Convey("Every ID in the space gets its own code", t, func() {
+
+		cf := New()
+		cf.SetFormat("ddd")
+		cf.SetSalt([]byte("pepper"))
+
+		seen := map[string]bool{}
+		inOrder := 0
+		for id := int64(0); id < 1000; id++ {
+			code, err := cf.EncodeID(id)
+			So(err, ShouldBeNil)
+			seen[code] = true
+			if code == fmt.Sprintf("%03d", id) {
+				inOrder++
+			}
+		}
+		So(len(seen), ShouldEqual, 1000)
+		So(inOrder, ShouldBeLessThan, 10)
+
+		_, err := cf.EncodeID(1000)
+		So(err, ShouldEqual, ErrIDRange)
+		_, err = cf.EncodeID(-1)
+		So(err, ShouldEqual, ErrIDRange)
+	})
+
+	Convey("The salt changes the codes", t, func() {
+
+		a, b := New(), New()
+		a.SetSalt([]byte("pepper"))
+		b.SetSalt([]byte("paprika"))
+
+		codeA, _ := a.EncodeID(1)
+		codeB, _ := b.EncodeID(1)
+		So(codeA, ShouldNotEqual, codeB)
+
+		id, err := b.DecodeID(codeA)
+		So(err, ShouldBeNil)
+		So(id, ShouldNotEqual, 1)
+	})
+
+	Convey("A salt is needed", t, func() {
+
+		cf := New()
+		_, err := cf.EncodeID(42)
+		So(err, ShouldEqual, ErrNoSalt)
+		_, err = cf.DecodeID("#O9TD")
+		So(err, ShouldEqual, ErrNoSalt)
+
+		cf.SetSalt([]byte{})
+		_, err = cf.EncodeID(42)
+		So(err, ShouldEqual, ErrNoSalt)
+	})
+}
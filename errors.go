@@ -0,0 +1,81 @@
+package codefactory
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// CharError is returned when a string passed to a CodeFactory method contains
+// a character that it can't accept.  It wraps one of the Err values, such as
+// ErrInvalidFormat or ErrDuplicates.
+type CharError struct {
+	Err  error // the reason that the character was rejected
+	Rune rune  // the rejected character
+	Pos  int   // position of the character in the string, counted in runes
+}
+
+func (e *CharError) Error() string {
+	return fmt.Sprintf("%v: %q at position %d", e.Err, e.Rune, e.Pos)
+}
+
+// Unwrap returns the reason that the character was rejected.
+func (e *CharError) Unwrap() error {
+	return e.Err
+}
+
+// CountError is returned when more codes are requested than can be generated
+// with the settings of the CodeFactory.  It wraps ErrTooManyCodes.
+type CountError struct {
+	Requested  int64 // number of codes requested
+	Achievable int64 // maximum number of codes, as given by MaxCodes
+}
+
+func (e *CountError) Error() string {
+	return fmt.Sprintf("%v: requested %d, but at most %d can be generated",
+		ErrTooManyCodes, e.Requested, e.Achievable)
+}
+
+// Unwrap returns ErrTooManyCodes.
+func (e *CountError) Unwrap() error {
+	return ErrTooManyCodes
+}
+
+// charError returns a CharError for the rune at position i of s, counted in
+// runes.
+func charError(err error, s string, i int) *CharError {
+	return &CharError{Err: err, Rune: []rune(s)[i], Pos: i}
+}
+
+// firstRune returns the position, counted in runes, of the first rune in s
+// for which f returns true, or -1 if there is none.
+func firstRune(s string, f func(rune) bool) int {
+	for i, v := range []rune(s) {
+		if f(v) {
+			return i
+		}
+	}
+	return -1
+}
+
+func firstWhitespace(s string) int {
+	return firstRune(s, unicode.IsSpace)
+}
+
+func firstNotLatin1(s string) int {
+	return firstRune(s, func(v rune) bool {
+		return v > unicode.MaxLatin1
+	})
+}
+
+// firstDuplicate returns the position of the first rune in s that has already
+// appeared earlier in s, or -1 if there is none.
+func firstDuplicate(s string) int {
+	seen := map[rune]bool{}
+	return firstRune(s, func(v rune) bool {
+		if seen[v] {
+			return true
+		}
+		seen[v] = true
+		return false
+	})
+}
@@ -0,0 +1,95 @@
+package codefactory
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCharError(t *testing.T) {
+	var testCases = []struct {
+		desc string
+		call func(cf *CodeFactory) error
+		want *CharError
+	}{
+		{
+			desc: "invalid format letter",
+			call: func(cf *CodeFactory) error { return cf.SetFormat("#xxf") },
+			want: &CharError{Err: ErrInvalidFormat, Rune: 'f', Pos: 3},
+		},
+		{
+			desc: "invalid format digit after multi-byte rune",
+			call: func(cf *CodeFactory) error { return cf.SetFormat("€x1") },
+			want: &CharError{Err: ErrInvalidFormat, Rune: '1', Pos: 2},
+		},
+		{
+			desc: "whitespace in custom set",
+			call: func(cf *CodeFactory) error { return cf.SetCustom("ab c") },
+			want: &CharError{Err: ErrWhitespace, Rune: ' ', Pos: 2},
+		},
+		{
+			desc: "duplicate in custom set",
+			call: func(cf *CodeFactory) error { return cf.SetCustom("abca") },
+			want: &CharError{Err: ErrDuplicates, Rune: 'a', Pos: 3},
+		},
+		{
+			desc: "non Latin1 extension",
+			call: func(cf *CodeFactory) error { return cf.ExtendLetters("ñ您") },
+			want: &CharError{Err: ErrNotLatin1, Rune: '您', Pos: 1},
+		},
+		{
+			desc: "existing extension",
+			call: func(cf *CodeFactory) error { return cf.ExtendLetters("ñc") },
+			want: &CharError{Err: ErrAlreadyExist, Rune: 'c', Pos: 1},
+		},
+		{
+			desc: "non-letter extension",
+			call: func(cf *CodeFactory) error { return cf.ExtendLetters("ñ9") },
+			want: &CharError{Err: ErrNotLetter, Rune: '9', Pos: 1},
+		},
+		{
+			desc: "non-letter exclusion",
+			call: func(cf *CodeFactory) error { return cf.Exclude("ab$") },
+			want: &CharError{Err: ErrNotLetter, Rune: '$', Pos: 2},
+		},
+	}
+
+	for i, tt := range testCases {
+		Convey(fmt.Sprintf("Case # %d: %s", i, tt.desc), t, func() {
+
+			err := tt.call(New())
+
+			var ce *CharError
+			So(errors.As(err, &ce), ShouldBeTrue)
+			So(ce, ShouldResemble, tt.want)
+			So(errors.Is(err, tt.want.Err), ShouldBeTrue)
+		})
+	}
+
+	Convey("CharError describes the character", t, func() {
+
+		err := New().SetFormat("#xxf")
+		So(err.Error(), ShouldEqual, `invalid format character: 'f' at position 3`)
+	})
+}
+
+func TestCountError(t *testing.T) {
+
+	Convey("Requesting too many codes gives the achievable count", t, func() {
+
+		cf := New()
+		cf.SetFormat("# d")
+
+		_, err := cf.Generate(20)
+
+		var ce *CountError
+		So(errors.As(err, &ce), ShouldBeTrue)
+		So(ce.Requested, ShouldEqual, 20)
+		So(ce.Achievable, ShouldEqual, 10)
+		So(errors.Is(err, ErrTooManyCodes), ShouldBeTrue)
+		So(err.Error(), ShouldEqual,
+			"too many codes to generate with given settings: requested 20, but at most 10 can be generated")
+	})
+}
@@ -0,0 +1,179 @@
+package codefactory
+
+import "math"
+
+// field is a named value that is packed into the leading code characters.
+type field struct {
+	name  string
+	max   int64
+	value int64
+}
+
+// AddField declares a field named `name` holding values from 0 to `max`, such
+// as a batch number, an expiry week, or a value tier.  The fields are packed
+// together, in the order they were added, into the leading code characters of
+// the format, using their sets as a mixed-radix number.  The rest of the code
+// remains random.
+//
+// The value embedded in generated codes is 0 until it is set with SetField,
+// and can be read back from a code with Decode.
+//
+// The code characters holding the fields are the same for all codes generated
+// with the same field values, so they no longer count towards MaxCodes.
+func (cf *CodeFactory) AddField(name string, max int64) error {
+	for _, f := range cf.fields {
+		if f.name == name {
+			return ErrFieldExists
+		}
+	}
+	if max < 0 {
+		return ErrFieldRange
+	}
+	if max == math.MaxInt64 || cf.fieldSpace() > math.MaxInt64/(max+1) {
+		return ErrFieldsTooLarge
+	}
+	cf.fields = append(cf.fields, field{name: name, max: max})
+	return nil
+}
+
+// SetField sets the value of the field named `name` that is embedded in
+// generated codes.
+func (cf *CodeFactory) SetField(name string, value int64) error {
+	for i, f := range cf.fields {
+		if f.name != name {
+			continue
+		}
+		if value < 0 || value > f.max {
+			return ErrFieldRange
+		}
+		cf.fields[i].value = value
+		return nil
+	}
+	return ErrUnknownField
+}
+
+// Decode returns the values of the fields embedded in a canonical code.  If
+// the format contains signature characters, the signature is checked first.
+// ErrPolicySet is returned while a policy is set.
+func (cf *CodeFactory) Decode(code string) (map[string]int64, error) {
+	res := map[string]int64{}
+
+	if cf.policy != nil {
+		return res, ErrPolicySet
+	}
+	if err := cf.checkFields(); err != nil {
+		return res, err
+	}
+	slots, body, err := cf.parse(code)
+	if err != nil {
+		return res, err
+	}
+	if cf.signed() {
+		if err := cf.checkSignature(slots, body); err != nil {
+			return res, err
+		}
+	}
+
+	// read the packed value from the leading field characters
+	v := int64(0)
+	for j, s := range slots {
+		if !s.field {
+			continue
+		}
+		n, d := int64(len(s.vals)), int64(indexOf(s.vals, body[j]))
+		if v > (math.MaxInt64-d)/n {
+			return map[string]int64{}, ErrInvalidCode
+		}
+		v = v*n + d
+	}
+	if v >= cf.fieldSpace() {
+		return map[string]int64{}, ErrInvalidCode
+	}
+
+	// unpack the fields from the last one backwards
+	for i := len(cf.fields) - 1; i >= 0; i-- {
+		n := cf.fields[i].max + 1
+		res[cf.fields[i].name] = v % n
+		v /= n
+	}
+	return res, nil
+}
+
+// fieldSpace returns the number of combinations of the field values.
+func (cf *CodeFactory) fieldSpace() int64 {
+	space := int64(1)
+	for _, f := range cf.fields {
+		space *= f.max + 1
+	}
+	return space
+}
+
+// markFieldSlots marks as many leading random slots as are needed to hold the
+// fields.  If the format is too short, all random slots are marked.
+func (cf *CodeFactory) markFieldSlots(slots []slot) {
+	if len(cf.fields) == 0 {
+		return
+	}
+	space := cf.fieldSpace()
+	capacity := int64(1)
+	for j := range slots {
+		if capacity >= space {
+			return
+		}
+		if !slots[j].random() {
+			continue
+		}
+		slots[j].field = true
+		capacity = mulCapped(capacity, int64(len(slots[j].vals)))
+	}
+}
+
+// checkFields returns an error if the fields don't fit in the format.
+func (cf *CodeFactory) checkFields() error {
+	if len(cf.fields) == 0 {
+		return nil
+	}
+	capacity := int64(1)
+	for _, s := range cf.slots() {
+		if s.field {
+			capacity = mulCapped(capacity, int64(len(s.vals)))
+		}
+	}
+	if capacity < cf.fieldSpace() {
+		return ErrFieldsTooLarge
+	}
+	return nil
+}
+
+// packFields fills the field slots of body with the packed field values.
+func (cf *CodeFactory) packFields(slots []slot, body []string) {
+	v := int64(0)
+	for _, f := range cf.fields {
+		v = v*(f.max+1) + f.value
+	}
+	for j := len(slots) - 1; j >= 0; j-- {
+		if !slots[j].field {
+			continue
+		}
+		n := int64(len(slots[j].vals))
+		body[j] = slots[j].vals[v%n]
+		v /= n
+	}
+}
+
+// mulCapped multiplies a and b, limiting the result to math.MaxInt64.
+func mulCapped(a, b int64) int64 {
+	if b != 0 && a > math.MaxInt64/b {
+		return math.MaxInt64
+	}
+	return a * b
+}
+
+func indexOf(vals []string, v string) int {
+	for i, s := range vals {
+		if s == v {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,123 @@
+package codefactory
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAddField(t *testing.T) {
+
+	Convey("Fields need unique names and a valid range", t, func() {
+
+		cf := New()
+		So(cf.AddField("batch", 999), ShouldBeNil)
+		So(cf.AddField("batch", 10), ShouldEqual, ErrFieldExists)
+		So(cf.AddField("tier", -1), ShouldEqual, ErrFieldRange)
+		So(cf.AddField("huge", math.MaxInt64), ShouldEqual, ErrFieldsTooLarge)
+		So(cf.AddField("large", math.MaxInt64/100), ShouldEqual, ErrFieldsTooLarge)
+		So(len(cf.fields), ShouldEqual, 1)
+	})
+
+	Convey("Field values must be in range", t, func() {
+
+		cf := New()
+		cf.AddField("tier", 7)
+
+		So(cf.SetField("tier", 7), ShouldBeNil)
+		So(cf.SetField("tier", 8), ShouldEqual, ErrFieldRange)
+		So(cf.SetField("tier", -1), ShouldEqual, ErrFieldRange)
+		So(cf.SetField("batch", 1), ShouldEqual, ErrUnknownField)
+		So(cf.fields[0].value, ShouldEqual, 7)
+	})
+
+	Convey("Fields take the leading code characters", t, func() {
+
+		cf := New()
+		cf.SetFormat("#dddd-dddd")
+		So(cf.MaxCodes(), ShouldEqual, maxNumCodes)
+
+		// 1000 * 53 * 8 = 424,000 values need 6 digits
+		cf.AddField("batch", 999)
+		cf.AddField("week", 52)
+		cf.AddField("tier", 7)
+		So(cf.MaxCodes(), ShouldEqual, 100)
+	})
+}
+
+func TestDecode(t *testing.T) {
+
+	Convey("Decode reads back the fields of generated codes", t, func() {
+
+		cf := New()
+		cf.SetFormat("xxxx-xxxx")
+		cf.AddField("batch", 999)
+		cf.AddField("week", 52)
+		cf.AddField("tier", 7)
+		cf.SetField("batch", 123)
+		cf.SetField("week", 42)
+		cf.SetField("tier", 5)
+
+		codes, err := cf.Generate(100)
+		So(err, ShouldBeNil)
+
+		for _, code := range codes {
+			So(code[:3], ShouldEqual, codes[0][:3])
+			fields, err := cf.Decode(code)
+			So(err, ShouldBeNil)
+			So(fields, ShouldResemble, map[string]int64{
+				"batch": 123,
+				"week":  42,
+				"tier":  5,
+			})
+		}
+	})
+
+	Convey("Decode checks the signature", t, func() {
+
+		cf := New()
+		cf.SetFormat("dddddd-ss")
+		cf.SetKey([]byte("secret"))
+		cf.AddField("tier", 7)
+		cf.SetField("tier", 3)
+
+		codes, err := cf.Generate(1)
+		So(err, ShouldBeNil)
+
+		fields, err := cf.Decode(codes[0])
+		So(err, ShouldBeNil)
+		So(fields["tier"], ShouldEqual, 3)
+
+		forged := "4" + codes[0][1:]
+		fields, err = cf.Decode(forged)
+		So(err, ShouldEqual, ErrBadSignature)
+		So(fields, ShouldResemble, map[string]int64{})
+	})
+
+	Convey("Decode rejects values out of range", t, func() {
+
+		cf := New()
+		cf.SetFormat("dddd")
+		cf.AddField("tier", 7)
+
+		_, err := cf.Decode("8123")
+		So(err, ShouldEqual, ErrInvalidCode)
+		_, err = cf.Decode("12a4")
+		So(err, ShouldEqual, ErrInvalidCode)
+	})
+
+	Convey("Fields must fit in the format", t, func() {
+
+		cf := New()
+		cf.SetFormat("dd")
+		cf.AddField("batch", 999)
+
+		res, err := cf.Generate(1)
+		So(err, ShouldEqual, ErrFieldsTooLarge)
+		So(res, ShouldResemble, []string{})
+
+		_, err = cf.Decode("12")
+		So(err, ShouldEqual, ErrFieldsTooLarge)
+	})
+}
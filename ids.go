@@ -0,0 +1,59 @@
+package codefactory
+
+const (
+	nanoIDAlphabet = "_-0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	nanoIDFormat   = "ccccccccccccccccccccc"
+	ulidFormat     = "tttttttttt" + "xxxxxxxxxxxxxxxx"
+	uuidVariant    = "89ab"
+	uuidV4Format   = `xxxxxxxx-xxxx-\4xxx-cxxx-xxxxxxxxxxxx`
+	uuidV7Format   = `tttttttt-tttt-\7xxx-cxxx-xxxxxxxxxxxx`
+)
+
+// NewNanoID generates a CodeFactory for NanoIDs, which are 21 characters from
+// the URL-safe alphabet "A-Za-z0-9_-", giving 126 random bits.
+//
+// The alphabet is held in the custom set.  NanoIDs are case sensitive, so
+// Normalize neither folds case nor maps any look-alikes.
+func NewNanoID() *CodeFactory {
+	cf := newAlphabet(nanoIDAlphabet)
+	cf.format = nanoIDFormat
+	cf.strictCase = true
+	return cf
+}
+
+// NewULID generates a CodeFactory for ULIDs, which are 26 characters of
+// Crockford's Base32 alphabet: 10 time characters holding the time in
+// milliseconds, followed by 80 random bits.  ULIDs sort by the time at which
+// they were generated.
+func NewULID() *CodeFactory {
+	cf := NewCrockford()
+	cf.format = ulidFormat
+	return cf
+}
+
+// NewUUIDv4 generates a CodeFactory for random (version 4) UUIDs as defined
+// by RFC 9562, such as "0f8fad5b-d9cb-469f-a165-70867728950e", with 122
+// random bits.
+//
+// The variant is held in the custom set, and the version digit is a literal
+// escaped with a backslash, which is set as the escape character.  Normalize
+// accepts uppercase letters, but doesn't map any look-alikes.
+func NewUUIDv4() *CodeFactory {
+	cf := NewHex()
+	cf.custom = uuidVariant
+	cf.escape = backslash
+	cf.format = uuidV4Format
+	return cf
+}
+
+// NewUUIDv7 generates a CodeFactory for time-ordered (version 7) UUIDs as
+// defined by RFC 9562, which hold the time in milliseconds in the first 12
+// hex digits, followed by 74 random bits.
+//
+// The variant is held in the custom set.  Normalize accepts uppercase letters,
+// but doesn't map any look-alikes.
+func NewUUIDv7() *CodeFactory {
+	cf := NewUUIDv4()
+	cf.format = uuidV7Format
+	return cf
+}
@@ -0,0 +1,169 @@
+package codefactory
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIDs(t *testing.T) {
+	var testCases = []struct {
+		desc    string
+		cf      *CodeFactory
+		pattern string
+		upper   bool
+	}{
+		{
+			desc:    "NanoID",
+			cf:      NewNanoID(),
+			pattern: `^[A-Za-z0-9_-]{21}$`,
+		},
+		{
+			desc:    "ULID",
+			cf:      NewULID(),
+			pattern: `^[0-7][0-9A-HJKMNP-TV-Z]{25}$`,
+		},
+		{
+			desc:    "UUIDv4",
+			cf:      NewUUIDv4(),
+			pattern: `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+			upper:   true,
+		},
+		{
+			desc:    "UUIDv7",
+			cf:      NewUUIDv7(),
+			pattern: `^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+			upper:   true,
+		},
+	}
+
+	for i, tt := range testCases {
+		Convey(fmt.Sprintf("Case # %d: %s", i, tt.desc), t, func() {
+
+			res, err := tt.cf.Generate(1000)
+			So(err, ShouldBeNil)
+			So(len(res), ShouldEqual, 1000)
+
+			re := regexp.MustCompile(tt.pattern)
+			for _, id := range res {
+				So(re.MatchString(id), ShouldBeTrue)
+				So(tt.cf.Validate(id), ShouldBeNil)
+			}
+
+			if tt.upper {
+				got, err := tt.cf.Normalize(strings.ToUpper(res[0]))
+				So(err, ShouldBeNil)
+				So(got, ShouldEqual, res[0])
+			}
+		})
+	}
+
+	Convey("ULIDs and UUIDv7s hold the time", t, func() {
+
+		now := time.Unix(1700000000, 123000000)
+		for _, cf := range []*CodeFactory{NewULID(), NewUUIDv7()} {
+			cf.now = func() time.Time { return now }
+
+			res, err := cf.Generate(1)
+			So(err, ShouldBeNil)
+			got, err := cf.Time(res[0])
+			So(err, ShouldBeNil)
+			So(got.Equal(now), ShouldBeTrue)
+		}
+
+		cf := NewUUIDv7()
+		cf.now = func() time.Time { return now }
+		res, _ := cf.Generate(1)
+		So(res[0][:13], ShouldEqual, fmt.Sprintf("%08x-%04x", now.UnixMilli()>>16, now.UnixMilli()&0xffff))
+	})
+}
+
+func TestEscapedFormat(t *testing.T) {
+	var testCases = []struct {
+		desc    string
+		escape  rune
+		format  string
+		input   string
+		want    string
+		wantErr error
+	}{
+		{
+			desc:   "escaped letters and numbers",
+			escape: backslash,
+			format: `\Ad\x-\\d`,
+			input:  "a1 x 2",
+			want:   `A1x-\2`,
+		},
+		{
+			desc:   "escaped format characters aren't code characters",
+			escape: backslash,
+			format: `\s\td`,
+			input:  "ST7",
+			want:   "st7",
+		},
+		{
+			desc:   "another escape character",
+			escape: '~',
+			format: `~4d\d`,
+			input:  `41\2`,
+			want:   `41\2`,
+		},
+		{
+			desc:   "a backslash is a literal without an escape",
+			format: `dd\dd`,
+			input:  `12\34`,
+			want:   `12\34`,
+		},
+		{
+			desc:   "a trailing backslash is a literal without an escape",
+			format: `dd\`,
+			input:  `12\`,
+			want:   `12\`,
+		},
+		{
+			desc:    "trailing escape",
+			escape:  backslash,
+			format:  `dd\`,
+			wantErr: ErrInvalidFormat,
+		},
+	}
+
+	for i, tt := range testCases {
+		Convey(fmt.Sprintf("Case # %d: %s", i, tt.desc), t, func() {
+
+			cf := New()
+			So(cf.SetEscape(tt.escape), ShouldBeNil)
+			err := cf.SetFormat(tt.format)
+			if tt.wantErr != nil {
+				So(err, ShouldResemble, &CharError{Err: tt.wantErr, Rune: '\\', Pos: 2})
+				return
+			}
+			So(err, ShouldBeNil)
+			So(cf.signed(), ShouldBeFalse)
+			So(cf.timed(), ShouldBeFalse)
+
+			got, err := cf.Normalize(tt.input)
+			So(err, ShouldBeNil)
+			So(got, ShouldEqual, tt.want)
+			So(cf.Validate(got), ShouldBeNil)
+		})
+	}
+
+	Convey("The escape character is checked against the format", t, func() {
+
+		cf := New()
+		So(cf.SetEscape('a'), ShouldResemble, &CharError{Err: ErrInvalidFormat, Rune: 'a', Pos: 0})
+		So(cf.SetFormat(`dd\`), ShouldBeNil)
+		So(cf.SetEscape(backslash), ShouldResemble, &CharError{Err: ErrInvalidFormat, Rune: '\\', Pos: 2})
+		So(cf.SetFormat(`d\d`), ShouldBeNil)
+		So(cf.SetEscape(backslash), ShouldBeNil)
+		So(cf.SetFormat(`d\d`), ShouldBeNil)
+		So(cf.slots(), ShouldHaveLength, 2)
+		So(cf.SetEscape(0), ShouldBeNil)
+		So(cf.slots(), ShouldHaveLength, 3)
+	})
+}
@@ -0,0 +1,199 @@
+package codefactory
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Index holds a set of issued codes, and suggests which of them a person most
+// likely meant when they typed a code that doesn't validate.
+//
+// The code characters are split into blocks in the same way as for
+// SetMinDistance, so a lookup only compares the input with the codes that
+// share a block with it, even for millions of codes.  The blocks for each
+// number of edits are laid out the first time Suggest is asked for it.
+// Suggest may be called from several goroutines at once, but Add may not be
+// called at the same time as Suggest.
+type Index struct {
+	cf    *CodeFactory
+	used  string
+	codes []string
+	keys  [][]rune
+
+	mu   sync.Mutex
+	near map[int]*nearIndex // by the number of edits suggested for
+}
+
+// Suggestion is a code suggested by an Index, along with the number of edits
+// that turn the input into the code.
+type Suggestion struct {
+	Code     string
+	Distance int
+}
+
+// Index returns an Index of the codes.  The Index keeps a copy of the settings
+// of the CodeFactory at the time it is created, which are used to read typed
+// input in the same way as Normalize.
+func (cf *CodeFactory) Index(codes []string) *Index {
+	c := cf.clone()
+	ix := &Index{
+		cf:   c,
+		used: strings.Join(c.typedSets(c.slots()), ""),
+		near: map[int]*nearIndex{},
+	}
+	for _, code := range codes {
+		ix.Add(code)
+	}
+	return ix
+}
+
+// Add adds a code to the Index.
+func (ix *Index) Add(code string) {
+	key := ix.key(code)
+	ix.codes = append(ix.codes, code)
+	ix.keys = append(ix.keys, key)
+	for _, ni := range ix.near {
+		ni.add(key)
+	}
+}
+
+// Len returns the number of codes in the Index.
+func (ix *Index) Len() int {
+	return len(ix.codes)
+}
+
+// Suggest returns the codes that are within `k` edits of the input, with the
+// most likely first.  Edits that swap a character for a look-alike, such as O
+// for 0 or l for 1, are taken to be more likely than others, following the
+// look-alike rules of the CodeFactory.  Letter case is ignored unless the
+// CodeFactory is case sensitive.
+func (ix *Index) Suggest(input string, k int) []Suggestion {
+	res := []Suggestion{}
+	if len(ix.codes) == 0 || k < 0 {
+		return res
+	}
+
+	typed := ix.cf.typedChars(input, ix.used)
+	key := ix.fold(typed)
+	cost := map[string]float64{}
+
+	ix.nearIndex(k).candidates(key, func(id int) bool {
+		if editBound(key, ix.keys[id]) > k {
+			return true
+		}
+		if d := damerauLevenshtein(key, ix.keys[id]); d <= k {
+			code := ix.codes[id]
+			res = append(res, Suggestion{Code: code, Distance: d})
+			cost[code] = ix.cf.typoCost(typed, ix.cf.typedChars(code, ix.used))
+		}
+		return true
+	})
+
+	sort.Slice(res, func(i, j int) bool {
+		a, b := res[i], res[j]
+		if cost[a.Code] != cost[b.Code] {
+			return cost[a.Code] < cost[b.Code]
+		}
+		if a.Distance != b.Distance {
+			return a.Distance < b.Distance
+		}
+		return a.Code < b.Code
+	})
+	return res
+}
+
+// nearIndex returns the blocks that find the codes within k edits of an
+// input, laying them out the first time k is asked for.
+func (ix *Index) nearIndex(k int) *nearIndex {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ni, ok := ix.near[k]
+	if !ok {
+		ni = newNearIndex(k+1, DamerauLevenshtein)
+		for _, key := range ix.keys {
+			ni.add(key)
+		}
+		ix.near[k] = ni
+	}
+	return ni
+}
+
+// key returns the code characters of a code, with their case folded unless
+// the CodeFactory is case sensitive.
+func (ix *Index) key(code string) []rune {
+	return ix.fold(ix.cf.typedChars(code, ix.used))
+}
+
+func (ix *Index) fold(chars []rune) []rune {
+	if ix.cf.strictCase {
+		return chars
+	}
+	res := make([]rune, len(chars))
+	for i, v := range chars {
+		res[i] = unicode.ToLower(v)
+	}
+	return res
+}
+
+// typoCost returns the cost of the edits that turn the typed characters into
+// those of a code.  Case differences are free unless the CodeFactory is case
+// sensitive, look-alikes cost half an edit, and other edits cost one.
+func (cf *CodeFactory) typoCost(a, b []rune) float64 {
+	d := make([][]float64, len(a)+1)
+	for i := range d {
+		d[i] = make([]float64, len(b)+1)
+		d[i][0] = float64(i)
+	}
+	for j := range d[0] {
+		d[0][j] = float64(j)
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			sub := 1.0
+			if a[i-1] == b[j-1] || (!cf.strictCase && unicode.ToLower(a[i-1]) == unicode.ToLower(b[j-1])) {
+				sub = 0
+			} else if cf.confusable(a[i-1], b[j-1]) {
+				sub = 0.5
+			}
+			d[i][j] = minFloat(d[i-1][j-1]+sub, d[i-1][j]+1, d[i][j-1]+1)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = minFloat(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+// confusable reports whether a person could have typed a in place of b, by
+// the aliases of a preset or else the general look-alikes.
+func (cf *CodeFactory) confusable(a, b rune) bool {
+	if cf.aliases != nil {
+		return cf.aliases[a] == b || cf.aliases[b] == a
+	}
+	for _, group := range lookalikes {
+		if cf.inGroup(group, a) && cf.inGroup(group, b) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cf *CodeFactory) inGroup(group string, r rune) bool {
+	if isIncludedIn(group, r) {
+		return true
+	}
+	return !cf.strictCase &&
+		(isIncludedIn(group, unicode.ToUpper(r)) || isIncludedIn(group, unicode.ToLower(r)))
+}
+
+func minFloat(v ...float64) float64 {
+	m := v[0]
+	for _, n := range v[1:] {
+		if n < m {
+			m = n
+		}
+	}
+	return m
+}
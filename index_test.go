@@ -0,0 +1,151 @@
+package codefactory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIndexSuggest(t *testing.T) {
+	var testCases = []struct {
+		desc  string
+		cf    *CodeFactory
+		codes []string
+		input string
+		k     int
+		want  []Suggestion
+	}{
+		{
+			desc:  "look-alikes rank first",
+			cf:    New(),
+			codes: []string{"AB10-CD55", "AB17-CD55", "ZZ99-ZZ99"},
+			input: "ab1o cd5s",
+			k:     2,
+			want:  []Suggestion{{"AB10-CD55", 2}, {"AB17-CD55", 2}},
+		},
+		{
+			desc:  "swapped and missing characters",
+			cf:    New(),
+			codes: []string{"AB10-CD55", "AB17-CD55", "ZZ99-ZZ99"},
+			input: "BA10CD5",
+			k:     2,
+			want:  []Suggestion{{"AB10-CD55", 2}},
+		},
+		{
+			desc:  "exact match",
+			cf:    New(),
+			codes: []string{"AB10-CD55", "AB17-CD55"},
+			input: "AB10-CD55",
+			k:     1,
+			want:  []Suggestion{{"AB10-CD55", 0}, {"AB17-CD55", 1}},
+		},
+		{
+			desc:  "aliases of a preset",
+			cf:    NewCrockford(),
+			codes: []string{"0123ABCD", "Q123ABCD"},
+			input: "o123abcd",
+			k:     1,
+			want:  []Suggestion{{"0123ABCD", 1}, {"Q123ABCD", 1}},
+		},
+		{
+			desc:  "case sensitive preset",
+			cf:    NewBase58(),
+			codes: []string{"abcdefgh", "ABCDEFGH"},
+			input: "abcdefgH",
+			k:     1,
+			want:  []Suggestion{{"abcdefgh", 1}},
+		},
+		{
+			desc:  "nothing close enough",
+			cf:    New(),
+			codes: []string{"AB10-CD55"},
+			input: "ZZ99-ZZ99",
+			k:     2,
+			want:  []Suggestion{},
+		},
+	}
+
+	for i, tt := range testCases {
+		Convey(fmt.Sprintf("Case # %d: %s", i, tt.desc), t, func() {
+
+			tt.cf.SetFormat(strings.Map(func(r rune) rune {
+				if r == '-' {
+					return r
+				}
+				return 'x'
+			}, tt.codes[0]))
+
+			ix := tt.cf.Index(tt.codes)
+			So(ix.Len(), ShouldEqual, len(tt.codes))
+			So(ix.Suggest(tt.input, tt.k), ShouldResemble, tt.want)
+		})
+	}
+
+	Convey("Suggestions from many codes match a full scan", t, func() {
+
+		cf := New()
+		cf.SetFormat("wwww-wwww")
+		cf.SetSeed(1)
+		codes, err := cf.Generate(20000)
+		So(err, ShouldBeNil)
+		ix := cf.Index(codes)
+
+		inputs := []string{}
+		for _, code := range codes[:5] {
+			inputs = append(inputs,
+				"z"+code[1:4]+code[5:],                // substituted
+				code[:2]+code[3:4]+code[5:],           // deleted
+				code[:4]+"zz"+code[5:],                // inserted
+				code[1:2]+code[:1]+code[2:4]+code[5:]) // transposed
+		}
+		for n, input := range inputs {
+			code := codes[n/4]
+
+			want := []string{}
+			for _, c := range codes {
+				if DamerauLevenshtein.Distance(strings.Replace(c, "-", "", 1), input) <= 2 {
+					want = append(want, c)
+				}
+			}
+			got := []string{}
+			for _, s := range ix.Suggest(input, 2) {
+				got = append(got, s.Code)
+			}
+			sort.Strings(want)
+			sort.Strings(got)
+			So(got, ShouldResemble, want)
+			So(got, ShouldContain, code)
+		}
+	})
+}
+
+var benchIndex struct {
+	sync.Once
+	ix    *Index
+	codes []string
+}
+
+func benchSuggest(k int, b *testing.B) {
+	benchIndex.Do(func() {
+		cf := New()
+		cf.SetFormat("wwww-wwww")
+		cf.SetSeed(1)
+		benchIndex.codes, _ = cf.Generate(500000)
+		benchIndex.ix = cf.Index(benchIndex.codes)
+	})
+	ix, codes := benchIndex.ix, benchIndex.codes
+	ix.Suggest(codes[0], k)
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		code := codes[n%len(codes)]
+		ix.Suggest("z"+code[1:4]+code[5:], k)
+	}
+}
+
+func BenchmarkSuggest1(b *testing.B) { benchSuggest(1, b) }
+func BenchmarkSuggest2(b *testing.B) { benchSuggest(2, b) }
@@ -0,0 +1,302 @@
+package codefactory
+
+import (
+	"math"
+	"math/big"
+	"strings"
+	"unicode"
+)
+
+const (
+	// literalOdds is the largest chance of a random code character taking
+	// the same value in every sample for it to be taken as a literal.
+	literalOdds = 1e-3
+
+	// exclusionCount is the number of times a character is expected to be
+	// seen in the samples before its absence is taken as an exclusion.
+	exclusionCount = 5
+)
+
+// Inference is a CodeFactory inferred from sample codes by Infer.
+type Inference struct {
+	Factory *CodeFactory // the inferred settings
+	Fits    []Fit        // how well each distinct sample fits, in the order given
+	Matched int          // number of distinct samples that fit
+}
+
+// Fit is how well a sample code fits the format of an inferred CodeFactory.
+type Fit struct {
+	Code  string  // the sample as given
+	Score float64 // fraction of the characters of the code that fit, from 0 to 1
+	Err   error   // the error returned by Validate, or nil if the code fits
+}
+
+// Infer proposes the settings of a CodeFactory that could have generated the
+// sample codes, such as those of a legacy code list: a prefix and suffix, a
+// format, and character sets.  Characters that are expected to have been seen
+// in the samples at least five times, but never were, such as O in a list that
+// avoids look-alikes, are excluded from their sets.
+//
+// The format is inferred from the samples of the most common length.  A
+// position where every sample has the same letter or digit is taken as a
+// literal once there are enough samples to rule out chance, escaped with a
+// backslash as set by SetEscape, and positions with punctuation or symbols use
+// the custom set.  Larger samples give better guesses, so the Fits should be
+// checked before relying on the result.
+//
+// Whitespace around the samples is trimmed, and whitespace within them, such
+// as a tab, is read as a space, so samples copied from a spreadsheet or a
+// file still fit.  A position with a space in only some of the samples takes
+// its sets from the other samples, and the samples with the space don't fit.
+func Infer(samples []string) (*Inference, error) {
+	codes := []string{}
+	given := map[string]string{} // the first sample given for each code
+	for _, s := range samples {
+		c := cleanSample(s)
+		if _, ok := given[c]; c != "" && !ok {
+			given[c] = s
+			codes = append(codes, c)
+		}
+	}
+	if len(codes) == 0 {
+		return nil, ErrNoSamples
+	}
+
+	// the format is inferred from the codes of the most common length
+	counts := map[int]int{}
+	length := 0
+	for _, c := range codes {
+		n := len([]rune(c))
+		counts[n]++
+		if counts[n] > counts[length] || (counts[n] == counts[length] && n < length) {
+			length = n
+		}
+	}
+	chars := [][]rune{}
+	for _, c := range codes {
+		if r := []rune(c); len(r) == length {
+			chars = append(chars, r)
+		}
+	}
+
+	cf := New()
+	verbs := inferVerbs(chars)
+	if err := cf.inferSets(chars, verbs); err != nil {
+		return nil, err
+	}
+
+	// the leading and trailing literals are the prefix and suffix
+	lo, hi := 0, length
+	for lo < hi && verbs[lo] == 0 {
+		lo++
+	}
+	for hi > lo && verbs[hi-1] == 0 {
+		hi--
+	}
+	// literal letters and numbers within the code are escaped
+	for i := lo; i < hi; i++ {
+		if v := chars[0][i]; verbs[i] == 0 && (unicode.IsLetter(v) || unicode.IsNumber(v)) {
+			cf.SetEscape(backslash)
+			break
+		}
+	}
+	format := ""
+	for i := lo; i < hi; i++ {
+		v := chars[0][i]
+		switch {
+		case verbs[i] != 0:
+			format += string(verbs[i])
+		case cf.escape != 0 && (unicode.IsLetter(v) || unicode.IsNumber(v) || v == cf.escape):
+			format += string(cf.escape) + string(v)
+		default:
+			format += string(v)
+		}
+	}
+	if err := cf.SetPrefix(string(chars[0][:lo])); err != nil {
+		return nil, err
+	}
+	if err := cf.SetSuffix(string(chars[0][hi:])); err != nil {
+		return nil, err
+	}
+	if err := cf.SetFormat(format); err != nil {
+		return nil, err
+	}
+
+	in := &Inference{Factory: cf, Fits: []Fit{}}
+	for _, c := range codes {
+		f := cf.fit(c)
+		f.Code = given[c]
+		if f.Err == nil {
+			in.Matched++
+		}
+		in.Fits = append(in.Fits, f)
+	}
+	return in, nil
+}
+
+// CollisionRisk returns the probability that at least one of `num` codes
+// generated by the inferred CodeFactory is one of the samples that fit.  The
+// risk can be taken away by passing the samples to SetReserved.
+func (in *Inference) CollisionRisk(num int) float64 {
+	space, _ := new(big.Float).SetInt(spaceBig(in.Factory.slots())).Float64()
+	p := float64(in.Matched) / space
+	if p >= 1 {
+		return 1
+	}
+	return -math.Expm1(float64(num) * math.Log1p(-p))
+}
+
+// cleanSample trims the whitespace around a sample code, and turns whitespace
+// within it into spaces.
+func cleanSample(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		return r
+	}, strings.TrimSpace(s))
+}
+
+// inferVerbs returns the format character for each position of the codes, or
+// 0 where every code has the same character and it is taken as a literal.
+// Spaces can't be in a set, so they only make a position a literal.
+func inferVerbs(chars [][]rune) []rune {
+	verbs := make([]rune, len(chars[0]))
+	for i := range verbs {
+		var digit, upper, lower, other bool
+		same := true
+		for _, c := range chars {
+			v := c[i]
+			same = same && v == chars[0][i]
+			switch {
+			case v == ' ':
+			case isIncludedIn(defaultNumbers, v):
+				digit = true
+			case unicode.IsUpper(v) && v <= unicode.MaxLatin1:
+				upper = true
+			case unicode.IsLower(v) && v <= unicode.MaxLatin1:
+				lower = true
+			default:
+				other = true
+			}
+		}
+
+		size := 26.0
+		if digit {
+			size = 10
+		}
+		if same && (other || chars[0][i] == ' ' || math.Pow(1/size, float64(len(chars)-1)) < literalOdds) {
+			continue
+		}
+
+		switch {
+		case other:
+			verbs[i] = 'c'
+		case digit && upper && lower:
+			verbs[i] = 'x'
+		case digit && upper:
+			verbs[i] = 'p'
+		case digit && lower:
+			verbs[i] = 'w'
+		case upper && lower:
+			verbs[i] = 'a'
+		case upper:
+			verbs[i] = 'u'
+		case lower:
+			verbs[i] = 'l'
+		default:
+			verbs[i] = 'd'
+		}
+	}
+	return verbs
+}
+
+// inferSets sets the character sets of cf from the characters seen at the
+// code positions.  A character of a default set that was expected to be seen
+// often enough, but never was, is excluded.
+func (cf *CodeFactory) inferSets(chars [][]rune, verbs []rune) error {
+	seen := map[rune]bool{}
+	custom := ""
+	expected := map[rune]float64{}
+	for i, verb := range verbs {
+		if verb == 0 {
+			continue
+		}
+		for _, c := range chars {
+			v := c[i]
+			if v == ' ' {
+				continue
+			}
+			if verb == 'c' && !isIncludedIn(custom, v) {
+				custom += string(v)
+			}
+			seen[v] = true
+		}
+
+		// each character of the set is expected equally often
+		set := cf.set(verb)
+		for _, v := range set {
+			expected[v] += float64(len(chars)) / float64(len([]rune(set)))
+		}
+	}
+
+	extend := ""
+	for v := range seen {
+		if (unicode.IsUpper(v) || unicode.IsLower(v)) && v <= unicode.MaxLatin1 &&
+			!isIncludedIn(defaultUppercase+defaultLowercase, v) {
+			extend += string(v)
+		}
+	}
+	exclude := ""
+	for _, v := range defaultNumbers + defaultUppercase + defaultLowercase {
+		if !seen[v] && expected[v] >= exclusionCount {
+			exclude += string(v)
+		}
+	}
+
+	if err := cf.ExtendLetters(sortedSet(extend)); err != nil {
+		return err
+	}
+	if err := cf.Exclude(exclude); err != nil {
+		return err
+	}
+	return cf.SetCustom(custom)
+}
+
+// fit returns how well the code fits the format of cf.
+func (cf *CodeFactory) fit(code string) Fit {
+	f := Fit{Code: code, Score: 1, Err: cf.Validate(code)}
+	if f.Err == nil {
+		return f
+	}
+
+	// compare the code with the format one character at a time
+	want := []string{}
+	for _, v := range cf.prefix {
+		want = append(want, string(v))
+	}
+	for _, s := range cf.slots() {
+		if s.verb == 0 {
+			want = append(want, s.lit)
+		} else {
+			want = append(want, strings.Join(s.vals, ""))
+		}
+	}
+	for _, v := range cf.suffix {
+		want = append(want, string(v))
+	}
+
+	chars := []rune(code)
+	matched := 0
+	for i := 0; i < len(chars) && i < len(want); i++ {
+		if isIncludedIn(want[i], chars[i]) {
+			matched++
+		}
+	}
+	n := len(chars)
+	if len(want) > n {
+		n = len(want)
+	}
+	f.Score = float64(matched) / float64(n)
+	return f
+}
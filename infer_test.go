@@ -0,0 +1,168 @@
+package codefactory
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestInfer(t *testing.T) {
+	var testCases = []struct {
+		desc    string
+		samples []string
+		prefix  string
+		suffix  string
+		format  string
+		custom  string
+		matched int
+	}{
+		{
+			desc:    "separators",
+			samples: []string{"A1-x", "B2-y", "C3-z"},
+			format:  "ud-l",
+			matched: 3,
+		},
+		{
+			desc:    "constant letters with enough samples",
+			samples: []string{"X10!", "X22!", "X35!", "X47!"},
+			prefix:  "X",
+			suffix:  "!",
+			format:  "dd",
+			matched: 4,
+		},
+		{
+			desc:    "constant letters with too few samples",
+			samples: []string{"X1", "X2"},
+			format:  "ud",
+			matched: 2,
+		},
+		{
+			desc:    "constant digit within the code",
+			samples: []string{"A9B", "C9D", "E9F", "G9H", "J9K"},
+			format:  `u\9u`,
+			matched: 5,
+		},
+		{
+			desc:    "punctuation in the custom set",
+			samples: []string{"a!1", "b?2", "c!3"},
+			format:  "lcd",
+			custom:  "!?",
+			matched: 3,
+		},
+		{
+			desc:    "samples of another length",
+			samples: []string{"AB12", "CD34", "EF56", "GH7", "AB12", ""},
+			format:  "uudd",
+			matched: 3,
+		},
+		{
+			desc:    "whitespace around the samples",
+			samples: []string{"  AB-12", "CD-34\n", "\tEF-56 ", "GH-78", "GH-78 "},
+			format:  "uu-dd",
+			matched: 4,
+		},
+		{
+			desc:    "tabs and spaces within the samples",
+			samples: []string{"AB\t12", "CD 34", "EF\t56", "GH 78"},
+			format:  "uu dd",
+			matched: 4,
+		},
+		{
+			desc:    "constant tab after the prefix",
+			samples: []string{"PIN\t1234", "PIN\t5678", "PIN\t9012", "PIN\t3456"},
+			prefix:  "PIN ",
+			format:  "dddd",
+			matched: 4,
+		},
+		{
+			desc:    "space in some of the samples",
+			samples: []string{"AB-12", "C D-34", "EF-56", "GH-78"},
+			format:  "uu-dd",
+			matched: 3,
+		},
+		{
+			desc:    "space in some of the samples with punctuation",
+			samples: []string{"a!1", "b 2", "c?3"},
+			format:  "lcd",
+			custom:  "!?",
+			matched: 2,
+		},
+	}
+
+	for i, tt := range testCases {
+		Convey(fmt.Sprintf("Case # %d: %s", i, tt.desc), t, func() {
+
+			in, err := Infer(tt.samples)
+			So(err, ShouldBeNil)
+			cf := in.Factory
+			So(cf.prefix, ShouldEqual, tt.prefix)
+			So(cf.suffix, ShouldEqual, tt.suffix)
+			So(cf.format, ShouldEqual, tt.format)
+			So(cf.custom, ShouldEqual, tt.custom)
+			So(in.Matched, ShouldEqual, tt.matched)
+		})
+	}
+
+	Convey("A legacy code list is inferred with its exclusions", t, func() {
+
+		legacy := New()
+		legacy.Exclude("O0I1")
+		legacy.SetPrefix("PROMO-")
+		legacy.SetFormat("pppp-dd")
+		legacy.SetSeed(1)
+		codes, err := legacy.Generate(500)
+		So(err, ShouldBeNil)
+
+		in, err := Infer(codes)
+		So(err, ShouldBeNil)
+		So(in.Matched, ShouldEqual, 500)
+		So(in.Factory.prefix, ShouldEqual, "PROMO-")
+		So(in.Factory.format, ShouldEqual, "pppp-dd")
+
+		want, _ := legacy.Regexp()
+		got, _ := in.Factory.Regexp()
+		So(got, ShouldEqual, want)
+
+		space := 32.0 * 32 * 32 * 32 * 8 * 8
+		So(in.CollisionRisk(1), ShouldAlmostEqual, 500/space, 1e-12)
+		So(in.CollisionRisk(1000000), ShouldAlmostEqual, 0.9994, 1e-4)
+
+		in.Factory.SetReserved(codes)
+		in.Factory.SetSeed(2)
+		res, err := in.Factory.Generate(1000)
+		So(err, ShouldBeNil)
+		for _, code := range res {
+			So(codes, ShouldNotContain, code)
+		}
+	})
+
+	Convey("Samples that don't fit are scored", t, func() {
+
+		in, err := Infer([]string{"AB-12", "CD-34", "EF-56", "GH-7", "G1-78"})
+		So(err, ShouldBeNil)
+		So(in.Factory.format, ShouldEqual, "up-dd")
+		So(in.Fits, ShouldHaveLength, 5)
+		So(in.Fits[0], ShouldResemble, Fit{Code: "AB-12", Score: 1})
+		So(in.Fits[3].Err, ShouldEqual, ErrInvalidCode)
+		So(in.Fits[3].Score, ShouldEqual, 0.8)
+	})
+
+	Convey("Fits keep the samples as given", t, func() {
+
+		in, err := Infer([]string{" AB-12\n", "CD-34", "E F-56", "GH-78"})
+		So(err, ShouldBeNil)
+		So(in.Fits, ShouldHaveLength, 4)
+		So(in.Fits[0], ShouldResemble, Fit{Code: " AB-12\n", Score: 1})
+		So(in.Fits[2].Code, ShouldEqual, "E F-56")
+		So(in.Fits[2].Err, ShouldEqual, ErrInvalidCode)
+	})
+
+	Convey("Samples are needed", t, func() {
+
+		_, err := Infer(nil)
+		So(err, ShouldEqual, ErrNoSamples)
+		_, err = Infer([]string{"", " \t\n"})
+		So(err, ShouldEqual, ErrNoSamples)
+	})
+}
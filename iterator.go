@@ -0,0 +1,180 @@
+package codefactory
+
+import "strings"
+
+// Iterator walks through every code that a CodeFactory can generate.  The
+// codes are ordered by the position of each character in its set, with the
+// last code character changing fastest, so an Iterator over the format "dd"
+// counts from "00" to "99".
+//
+// Fields and signatures aren't iterated over: fields keep the values of the
+// start code, and signatures are worked out for each code.  Codes that are
+// reserved or contain a word on the blocklist are skipped.
+type Iterator struct {
+	cf     *CodeFactory
+	slots  []slot
+	body   []string
+	digits []int
+	parity *reedSolomon
+	fresh  bool
+}
+
+// Iterator returns an Iterator positioned at the canonical code `start`, or at
+// the first code if `start` is empty.  The first call to Next or Prev keeps
+// that position, so that
+//
+//	for it.Next() {
+//		fmt.Println(it.Code())
+//	}
+//
+// prints the start code and all the codes after it.  ErrPolicySet is
+// returned while a policy is set.
+func (cf *CodeFactory) Iterator(start string) (*Iterator, error) {
+	if cf.policy != nil {
+		return nil, ErrPolicySet
+	}
+	if err := cf.check(0); err != nil {
+		return nil, err
+	}
+
+	it := &Iterator{cf: cf, fresh: true}
+	if start == "" {
+		slots := cf.slots()
+		body, err := cf.newBody(slots)
+		if err != nil {
+			return nil, err
+		}
+		it.slots, it.body = slots, body
+		packCounter(it.slots, it.body, cf.counter)
+		unrank(it.slots, it.body, 0)
+	} else {
+		slots, body, err := cf.parse(start)
+		if err != nil {
+			return nil, err
+		}
+		it.slots, it.body = slots, body
+	}
+
+	it.parity, _ = cf.reedSolomon(it.slots)
+	it.digits = make([]int, len(it.slots))
+	for j, s := range it.slots {
+		if s.random() {
+			it.digits[j] = indexOf(s.vals, it.body[j])
+		}
+	}
+	return it, nil
+}
+
+// Next moves to the next code, and returns false if there are no more codes.
+func (it *Iterator) Next() bool {
+	return it.move(1)
+}
+
+// Prev moves to the previous code, and returns false if there are no earlier
+// codes.
+func (it *Iterator) Prev() bool {
+	return it.move(-1)
+}
+
+// Code returns the code at the current position.
+func (it *Iterator) Code() string {
+	it.fill()
+	return it.cf.prefix + strings.Join(it.body, "") + it.cf.suffix
+}
+
+// fill works out the signature and parity characters of the current code.
+func (it *Iterator) fill() {
+	if it.cf.signed() {
+		it.cf.sign(it.slots, it.body)
+	}
+	if it.parity != nil {
+		it.parity.encode(it.slots, it.body)
+	}
+}
+
+// move moves to the next code in the direction dir that isn't skipped.  If
+// there is none, the position is left unchanged.
+func (it *Iterator) move(dir int) bool {
+	last := len(it.slots) - 1
+	if it.fresh {
+		it.fresh = false
+		skip := false
+		if last, skip = it.skipped(); !skip {
+			return true
+		}
+	}
+	if len(it.cf.blocklist) == 0 && len(it.cf.reserved) == 0 {
+		return it.step(last, dir)
+	}
+
+	digits := append([]int{}, it.digits...)
+	body := append([]string{}, it.body...)
+	for last >= 0 && it.step(last, dir) {
+		skip := false
+		if last, skip = it.skipped(); !skip {
+			return true
+		}
+	}
+	it.digits, it.body = digits, body
+	return false
+}
+
+// skipped reports whether the current code is skipped, as it is reserved or
+// contains a word on the blocklist.  It also returns the last slot that the
+// next step has to move, which is earlier than the last slot when the codes
+// up to a change in that slot all contain the same blocked word, so that they
+// are skipped at once.  It is -1 if every code left contains the word.
+func (it *Iterator) skipped() (int, bool) {
+	last := len(it.slots) - 1
+	if len(it.cf.blocklist) == 0 && len(it.cf.reserved) == 0 {
+		return last, false
+	}
+	it.fill()
+	code := it.cf.prefix + strings.Join(it.body, "") + it.cf.suffix
+	if it.cf.reserved[code] {
+		return last, true
+	}
+	return it.cf.blockedSlot(it.slots, it.body)
+}
+
+// step moves one code forwards (dir = 1) or backwards (dir = -1), like an
+// odometer, counting slot `last` as the fastest changing one, so that the
+// slots after it are reset.  It leaves the position unchanged at either end of
+// the codes.
+func (it *Iterator) step(last, dir int) bool {
+
+	// find the last random slot that can move without wrapping
+	j := last
+	for ; j >= 0; j-- {
+		if !it.slots[j].random() {
+			continue
+		}
+		d := it.digits[j] + dir
+		if d >= 0 && d < len(it.slots[j].vals) {
+			break
+		}
+	}
+	if j < 0 {
+		return false
+	}
+
+	it.set(j, it.digits[j]+dir)
+
+	// wrap the slots after it
+	for k := j + 1; k < len(it.slots); k++ {
+		if !it.slots[k].random() {
+			continue
+		}
+		if dir > 0 {
+			it.set(k, 0)
+		} else {
+			it.set(k, len(it.slots[k].vals)-1)
+		}
+	}
+	return true
+}
+
+func (it *Iterator) set(j, d int) {
+	it.digits[j] = d
+	it.body[j] = it.slots[j].vals[d]
+}
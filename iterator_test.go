@@ -0,0 +1,134 @@
+package codefactory
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIterator(t *testing.T) {
+
+	Convey("Iterate over the whole space", t, func() {
+
+		cf := New()
+		cf.SetPrefix("PIN ")
+		cf.SetFormat("d-d")
+
+		it, err := cf.Iterator("")
+		So(err, ShouldBeNil)
+
+		res := []string{}
+		for it.Next() {
+			res = append(res, it.Code())
+		}
+		So(len(res), ShouldEqual, 100)
+		for i, code := range res {
+			So(code, ShouldEqual, fmt.Sprintf("PIN %d-%d", i/10, i%10))
+		}
+
+		Convey("and back again", func() {
+			So(it.Code(), ShouldEqual, "PIN 9-9")
+			So(it.Prev(), ShouldBeTrue)
+			So(it.Code(), ShouldEqual, "PIN 9-8")
+		})
+	})
+
+	Convey("Iterate from a start code", t, func() {
+
+		cf := New()
+		cf.SetFormat("#uu")
+
+		it, err := cf.Iterator("#AY")
+		So(err, ShouldBeNil)
+
+		So(it.Next(), ShouldBeTrue)
+		So(it.Code(), ShouldEqual, "#AY")
+		So(it.Next(), ShouldBeTrue)
+		So(it.Code(), ShouldEqual, "#AZ")
+		So(it.Next(), ShouldBeTrue)
+		So(it.Code(), ShouldEqual, "#BA")
+	})
+
+	Convey("Iterate backwards from a start code", t, func() {
+
+		cf := New()
+		cf.SetFormat("#uu")
+
+		it, err := cf.Iterator("#BA")
+		So(err, ShouldBeNil)
+
+		So(it.Prev(), ShouldBeTrue)
+		So(it.Code(), ShouldEqual, "#BA")
+		So(it.Prev(), ShouldBeTrue)
+		So(it.Code(), ShouldEqual, "#AZ")
+
+		it, err = cf.Iterator("#AB")
+		So(err, ShouldBeNil)
+		So(it.Prev(), ShouldBeTrue)
+		So(it.Prev(), ShouldBeTrue)
+		So(it.Code(), ShouldEqual, "#AA")
+		So(it.Prev(), ShouldBeFalse)
+		So(it.Code(), ShouldEqual, "#AA")
+	})
+
+	Convey("Iterate over signed codes with fields", t, func() {
+
+		cf := New()
+		cf.SetFormat("ddd-s")
+		cf.SetKey([]byte("secret"))
+		cf.AddField("tier", 9)
+		cf.SetField("tier", 7)
+
+		it, err := cf.Iterator("")
+		So(err, ShouldBeNil)
+
+		n := 0
+		for it.Next() {
+			code := it.Code()
+			So(code[0], ShouldEqual, '7')
+			So(cf.Validate(code), ShouldBeNil)
+			n++
+		}
+		So(n, ShouldEqual, 100)
+	})
+
+	Convey("Reserved codes are skipped", t, func() {
+
+		cf := New()
+		cf.SetPrefix("#")
+		cf.SetFormat("uu")
+		cf.SetReserved([]string{"#AA", "#AC", "#AD", "#ZZ"})
+
+		it, err := cf.Iterator("")
+		So(err, ShouldBeNil)
+		So(it.Next(), ShouldBeTrue)
+		So(it.Code(), ShouldEqual, "#AB")
+		So(it.Next(), ShouldBeTrue)
+		So(it.Code(), ShouldEqual, "#AE")
+		So(it.Prev(), ShouldBeTrue)
+		So(it.Code(), ShouldEqual, "#AB")
+		So(it.Prev(), ShouldBeFalse)
+		So(it.Code(), ShouldEqual, "#AB")
+
+		n := 1
+		for it.Next() {
+			n++
+		}
+		So(n, ShouldEqual, 26*26-4)
+		So(it.Code(), ShouldEqual, "#ZY")
+
+		// reserved codes are still valid, as they are handed out by hand
+		So(cf.Validate("#AC"), ShouldBeNil)
+	})
+
+	Convey("Invalid start codes are rejected", t, func() {
+
+		cf := New()
+		cf.SetFormat("#uu")
+
+		it, err := cf.Iterator("#A1")
+		So(err, ShouldEqual, ErrInvalidCode)
+		So(it, ShouldBeNil)
+	})
+}
@@ -0,0 +1,236 @@
+package codefactory
+
+import (
+	"strings"
+	"unicode"
+)
+
+// lookalikes are groups of characters that are easily mistaken for each other
+// when a code is read or typed by a person.
+var lookalikes = []string{"0Oo", "1Iil", "2Zz", "5Ss", "8B"}
+
+// Normalize converts a code as entered by a person into the canonical code
+// that the CodeFactory would have generated.  It is intended for checking
+// codes that have been typed in by customers, so that "ab12", "AB 12" and
+// "#AB12 " are all accepted as the code "#ab-12".
+//
+// Normalize:
+//   - trims whitespace, and ignores whitespace within the code
+//   - accepts the prefix and suffix in any case, with any separators, or not
+//     at all
+//   - strips any punctuation and symbols that are not part of a character set
+//     used by the format, and inserts the literal characters of the format
+//   - folds the case of letters that only exist in the other case in the set
+//     for that position, unless a look-alike is in the set as well, as O
+//     could be o or 0
+//   - maps look-alike characters, such as O to 0 and I to 1, when the typed
+//     character isn't in the set but exactly one of its look-alikes is
+//   - matches words from the word list, even when they are typed without the
+//     separators between them
+//
+// The presets, such as NewCrockford and NewBase58, replace the case folding
+// and look-alike rules with those of their standard alphabet.
+//
+// An error is returned if the input can't be mapped onto the format, and
+// ErrPolicySet while a policy is set.
+func (cf *CodeFactory) Normalize(code string) (string, error) {
+	if cf.policy != nil {
+		return "", ErrPolicySet
+	}
+
+	slots := cf.slots()
+	chars := cf.typedChars(code, strings.Join(cf.typedSets(slots), ""))
+
+	res := []string{}
+	cf.normalizeSlots(slots, chars, "", &res)
+	if len(res) != 1 {
+		return "", ErrInvalidCode
+	}
+	return cf.prefix + res[0] + cf.suffix, nil
+}
+
+// normalizeSlots adds to res each canonical code that the typed characters
+// can be read as, after the canonical characters in code.  Words typed
+// without their separators may be read in more than one way, so it stops once
+// it has found two different codes.
+func (cf *CodeFactory) normalizeSlots(slots []slot, chars []rune, code string, res *[]string) {
+	if len(*res) > 1 {
+		return
+	}
+	if len(slots) == 0 {
+		if len(chars) == 0 && (len(*res) == 0 || (*res)[0] != code) {
+			*res = append(*res, code)
+		}
+		return
+	}
+
+	s := slots[0]
+	switch {
+	// formatting symbol
+	case s.verb == 0 && !typed(s):
+		cf.normalizeSlots(slots[1:], chars, code+s.lit, res)
+	case s.verb == 'b':
+		for _, w := range s.vals {
+			n := len([]rune(w))
+			if n <= len(chars) && cf.sameWord(string(chars[:n]), w) {
+				cf.normalizeSlots(slots[1:], chars[n:], code+w, res)
+			}
+		}
+	case len(chars) > 0:
+		set := s.lit
+		if s.verb != 0 {
+			set = cf.set(s.verb)
+		}
+		if r, ok := cf.canonicalRune(chars[0], set); ok {
+			cf.normalizeSlots(slots[1:], chars[1:], code+string(r), res)
+		}
+	}
+}
+
+// typedSets returns the sets for each code character in the format, where
+// escaped letters and numbers have to be typed as they are.
+func (cf *CodeFactory) typedSets(slots []slot) []string {
+	sets := []string{}
+	for _, s := range slots {
+		if s.verb != 0 {
+			sets = append(sets, cf.set(s.verb))
+		} else if typed(s) {
+			sets = append(sets, s.lit)
+		}
+	}
+	return sets
+}
+
+// typedChars returns the characters of a code typed by a person that may be
+// code characters, leaving out whitespace, the prefix and suffix, and any
+// separators that aren't in the sets used by the format.  The prefix and
+// suffix lose their separators in the same way, so that they match however
+// their separators are typed.
+func (cf *CodeFactory) typedChars(code, used string) []rune {
+	in := typedOnly(code, used)
+	in = cutPrefixFold(in, typedOnly(cf.prefix, used))
+	in = cutSuffixFold(in, typedOnly(cf.suffix, used))
+	return []rune(in)
+}
+
+// typedOnly removes whitespace from s, along with any punctuation and symbols
+// that aren't in the sets used by the format.
+func typedOnly(s, used string) string {
+	return strings.Map(func(v rune) rune {
+		if unicode.IsSpace(v) ||
+			(unicode.IsPunct(v) || unicode.IsSymbol(v)) && !isIncludedIn(used, v) {
+			return -1
+		}
+		return v
+	}, s)
+}
+
+// typed reports whether a literal slot is a letter or number, which people
+// type along with the code characters.
+func typed(s slot) bool {
+	r := []rune(s.lit)[0]
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// set returns the characters that the format character v may be replaced with
+// in a code.
+func (cf *CodeFactory) set(v rune) string {
+	switch v {
+	case 'x', 's': // any, signature
+		return cf.num + cf.upper + cf.lower
+	case 'd': // digits
+		return cf.num
+	case 'l': // lowercase
+		return cf.lower
+	case 'w': // lowercase + number
+		return cf.lower + cf.num
+	case 'u': // uppercase
+		return cf.upper
+	case 'p': // uppercase + number
+		return cf.upper + cf.num
+	case 'a': // lowercase + uppercase
+		return cf.upper + cf.lower
+	case 'c': // custom
+		return cf.custom
+	case 'v': // vowels
+		return cf.letters(isVowel)
+	case 'k': // consonants
+		return cf.letters(func(r rune) bool { return !isVowel(r) })
+	case 't': // time
+		return sortedSet(cf.num + cf.upper + cf.lower)
+	case 'n', 'y', 'm', 'e', 'i', 'g': // counter, date
+		return AllValidDigits
+	case 'r': // parity
+		x := []rune(cf.num + cf.upper + cf.lower)
+		return string(x[:fieldSize(len(x))])
+	}
+	return ""
+}
+
+// canonicalRune maps the typed rune r onto the set, by folding its case or by
+// replacing it with a look-alike.  It returns false if there is no
+// unambiguous mapping, such as when O folds to o, but its look-alike 0 is in
+// the set as well.
+func (cf *CodeFactory) canonicalRune(r rune, set string) (rune, bool) {
+	if isIncludedIn(set, r) {
+		return r, true
+	}
+	variants := []rune{r}
+	found := []rune{}
+	if !cf.strictCase {
+		variants = append(variants, unicode.ToUpper(r), unicode.ToLower(r))
+		for _, v := range variants[1:] {
+			if isIncludedIn(set, v) && !isIncludedIn(string(found), v) {
+				found = append(found, v)
+			}
+		}
+	}
+
+	// the aliases of a preset replace the general look-alikes, and fold case
+	// as their standard alphabet does
+	if cf.aliases != nil {
+		if len(found) > 0 {
+			return found[0], true
+		}
+		if a, ok := cf.aliases[r]; ok && isIncludedIn(set, a) {
+			return a, true
+		}
+		return 0, false
+	}
+
+	for _, group := range lookalikes {
+		for _, v := range variants {
+			if !isIncludedIn(group, v) {
+				continue
+			}
+			for _, g := range group {
+				if isIncludedIn(set, g) && !isIncludedIn(string(found), g) {
+					found = append(found, g)
+				}
+			}
+			break
+		}
+	}
+	if len(found) != 1 {
+		return 0, false
+	}
+	return found[0], true
+}
+
+// cutPrefixFold removes the prefix from s if s starts with it, ignoring case.
+func cutPrefixFold(s, prefix string) string {
+	r, p := []rune(s), []rune(prefix)
+	if len(p) <= len(r) && strings.EqualFold(string(r[:len(p)]), prefix) {
+		return string(r[len(p):])
+	}
+	return s
+}
+
+// cutSuffixFold removes the suffix from s if s ends with it, ignoring case.
+func cutSuffixFold(s, suffix string) string {
+	r, p := []rune(s), []rune(suffix)
+	if len(p) <= len(r) && strings.EqualFold(string(r[len(r)-len(p):]), suffix) {
+		return string(r[:len(r)-len(p)])
+	}
+	return s
+}
@@ -0,0 +1,179 @@
+package codefactory
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNormalize(t *testing.T) {
+	var testCases = []struct {
+		desc    string
+		format  string
+		exclude string
+		prefix  string
+		suffix  string
+		input   string
+		want    string
+		wantErr error
+	}{
+		{
+			desc:   "canonical code",
+			format: "#ll-dd",
+			input:  "#ab-12",
+			want:   "#ab-12",
+		},
+		{
+			desc:   "missing separators",
+			format: "#ll-dd",
+			input:  "ab12",
+			want:   "#ab-12",
+		},
+		{
+			desc:   "wrong case and inner whitespace",
+			format: "#ll-dd",
+			input:  "AB 12",
+			want:   "#ab-12",
+		},
+		{
+			desc:   "wrong case and trailing whitespace",
+			format: "#ll-dd",
+			input:  "#AB12 ",
+			want:   "#ab-12",
+		},
+		{
+			desc:   "mixed case set keeps case",
+			format: "aa",
+			input:  "aB",
+			want:   "aB",
+		},
+		{
+			desc:    "look-alikes of excluded characters",
+			format:  "pppp",
+			exclude: "OI",
+			input:   "1O2I",
+			want:    "1021",
+		},
+		{
+			desc:   "look-alikes of included characters are kept",
+			format: "pppp",
+			input:  "1O2I",
+			want:   "1O2I",
+		},
+		{
+			desc:   "prefix and suffix in any case",
+			format: "dddd",
+			prefix: "Inv: ",
+			suffix: "-EU",
+			input:  "inv:1234-eu",
+			want:   "Inv: 1234-EU",
+		},
+		{
+			desc:   "prefix and suffix with other separators",
+			format: "dddd",
+			prefix: "Inv: ",
+			suffix: "-EU",
+			input:  "INV 1234 EU",
+			want:   "Inv: 1234-EU",
+		},
+		{
+			desc:   "prefix and suffix without separators",
+			format: "dddd",
+			prefix: "Inv: ",
+			suffix: "-EU",
+			input:  "inv1234eu",
+			want:   "Inv: 1234-EU",
+		},
+		{
+			desc:   "suffix without its separator",
+			format: "dddd",
+			prefix: "Inv: ",
+			suffix: "-EU",
+			input:  "inv:1234EU",
+			want:   "Inv: 1234-EU",
+		},
+		{
+			desc:   "prefix and suffix left out",
+			format: "dddd",
+			prefix: "Inv: ",
+			suffix: "-EU",
+			input:  "1234",
+			want:   "Inv: 1234-EU",
+		},
+		{
+			desc:    "too many characters",
+			format:  "#ll-dd",
+			input:   "abc12",
+			wantErr: ErrInvalidCode,
+		},
+		{
+			desc:    "too few characters",
+			format:  "#ll-dd",
+			input:   "ab1",
+			wantErr: ErrInvalidCode,
+		},
+		{
+			desc:    "character not in set",
+			format:  "#ll-dd",
+			input:   "a312",
+			wantErr: ErrInvalidCode,
+		},
+		{
+			desc:    "case folded onto a look-alike",
+			format:  "ww",
+			input:   "Oa",
+			wantErr: ErrInvalidCode,
+		},
+		{
+			desc:    "ambiguous look-alike",
+			format:  "ww",
+			exclude: "1",
+			input:   "1a",
+			wantErr: ErrInvalidCode,
+		},
+	}
+
+	for i, tt := range testCases {
+		Convey(fmt.Sprintf("Case # %d: %s", i, tt.desc), t, func() {
+
+			cf := New()
+			So(cf.SetFormat(tt.format), ShouldBeNil)
+			So(cf.Exclude(tt.exclude), ShouldBeNil)
+			So(cf.SetPrefix(tt.prefix), ShouldBeNil)
+			So(cf.SetSuffix(tt.suffix), ShouldBeNil)
+
+			res, err := cf.Normalize(tt.input)
+
+			So(res, ShouldEqual, tt.want)
+			So(err, ShouldEqual, tt.wantErr)
+		})
+	}
+
+	Convey("Case is only folded when no look-alike is in the set", t, func() {
+
+		cf := NewReadable()
+		_, err := cf.Normalize("#OOOO")
+		So(err, ShouldEqual, ErrInvalidCode)
+		res, err := cf.Normalize("#oooo")
+		So(err, ShouldBeNil)
+		So(res, ShouldEqual, "#oooo")
+		res, err = cf.Normalize("#AAAA")
+		So(err, ShouldBeNil)
+		So(res, ShouldEqual, "#aaaa")
+	})
+
+	Convey("Normalizing generated codes gives the same codes", t, func() {
+
+		cf := NewReadable()
+		cf.SetFormat("#ww-ww")
+		codes, err := cf.Generate(100)
+		So(err, ShouldBeNil)
+
+		for _, code := range codes {
+			res, err := cf.Normalize(code)
+			So(err, ShouldBeNil)
+			So(res, ShouldEqual, code)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package codefactory
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+)
+
+// Pair is a public serial code issued together with a secret code, such as
+// the serial number and PIN of a prepaid card.
+type Pair struct {
+	Serial string
+	Secret string
+}
+
+// GeneratePairs generates `num` pairs, taking the serials from the `serial`
+// CodeFactory and the secrets from the `secret` CodeFactory.  The serials are
+// unique, as are the secrets, and the pairs are returned ordered by serial.
+//
+// It returns an error if `num` is negative or either CodeFactory can't
+// generate `num` codes.
+func GeneratePairs(serial, secret *CodeFactory, num int) ([]Pair, error) {
+	if num < 0 {
+		return []Pair{}, ErrInvalidCount
+	}
+
+	serials, err := serial.Generate(num)
+	if err != nil {
+		return []Pair{}, err
+	}
+	secrets, err := secret.Generate(num)
+	if err != nil {
+		return []Pair{}, err
+	}
+
+	sort.Strings(serials)
+	res := make([]Pair, num)
+	for i := range res {
+		res[i] = Pair{Serial: serials[i], Secret: secrets[i]}
+	}
+	return res, nil
+}
+
+// WritePairs writes the pairs to `w` as CSV with a "serial,secret" header, so
+// that the same file can be given to a card printer and loaded by a backend.
+func WritePairs(w io.Writer, pairs []Pair) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"serial", "secret"}); err != nil {
+		return err
+	}
+	for _, p := range pairs {
+		if err := cw.Write([]string{p.Serial, p.Secret}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,83 @@
+package codefactory
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGeneratePairs(t *testing.T) {
+
+	Convey("Generate serial and PIN pairs", t, func() {
+
+		serial := New()
+		serial.SetPrefix("SN")
+		serial.SetFormat("dddddd")
+		pin := New()
+		pin.SetFormat("dddd-dddd")
+
+		pairs, err := GeneratePairs(serial, pin, 500)
+
+		So(err, ShouldBeNil)
+		So(len(pairs), ShouldEqual, 500)
+
+		serials := map[string]bool{}
+		secrets := map[string]bool{}
+		for _, p := range pairs {
+			So(p.Serial[:2], ShouldEqual, "SN")
+			So(len(p.Secret), ShouldEqual, 9)
+			serials[p.Serial] = true
+			secrets[p.Secret] = true
+		}
+		So(len(serials), ShouldEqual, 500)
+		So(len(secrets), ShouldEqual, 500)
+		So(sort.SliceIsSorted(pairs, func(i, j int) bool {
+			return pairs[i].Serial < pairs[j].Serial
+		}), ShouldBeTrue)
+	})
+
+	Convey("Errors from either CodeFactory are returned", t, func() {
+
+		serial := New()
+		serial.SetFormat("d")
+		pin := New()
+
+		pairs, err := GeneratePairs(serial, pin, 20)
+		So(errors.Is(err, ErrTooManyCodes), ShouldBeTrue)
+		So(pairs, ShouldResemble, []Pair{})
+
+		pairs, err = GeneratePairs(pin, serial, 20)
+		So(errors.Is(err, ErrTooManyCodes), ShouldBeTrue)
+		So(pairs, ShouldResemble, []Pair{})
+
+		pairs, err = GeneratePairs(pin, pin, -1)
+		So(err, ShouldEqual, ErrInvalidCount)
+		So(pairs, ShouldResemble, []Pair{})
+	})
+}
+
+func TestWritePairs(t *testing.T) {
+
+	Convey("Write pairs as CSV", t, func() {
+
+		pairs := []Pair{
+			{Serial: "SN0001", Secret: "1234"},
+			{Serial: "SN0002", Secret: "12,4"},
+		}
+		buf := &bytes.Buffer{}
+
+		err := WritePairs(buf, pairs)
+
+		So(err, ShouldBeNil)
+		So(buf.String(), ShouldEqual, strings.Join([]string{
+			"serial,secret",
+			"SN0001,1234",
+			`SN0002,"12,4"`,
+			"",
+		}, "\n"))
+	})
+}
@@ -0,0 +1,404 @@
+package codefactory
+
+import "strings"
+
+// binaryPolys are primitive polynomials for GF(2^m), indexed by m.
+var binaryPolys = []int{0, 0x3, 0x7, 0xb, 0x13, 0x25, 0x43, 0x89, 0x11d}
+
+// Correction is a character changed by Correct.
+type Correction struct {
+	Pos      int    // position in the code, counted in runes
+	From, To string // the character in the input and in the corrected code
+}
+
+// Correct repairs up to half as many wrong code characters as there are
+// parity characters ('r') in the format, and returns the corrected code along
+// with the characters it changed.  Characters that aren't in the set for
+// their position count as wrong characters.  Validate rejects codes whose
+// parity characters don't match with ErrBadParity, and Correct is the way to
+// repair them.  If the format contains signature characters, the corrected
+// code should still be checked with Validate.
+//
+// The parity characters are Reed-Solomon symbols over a finite field whose
+// size is a prime or a power of 2, and no larger than the set used by 'x'.
+// Each code character is a symbol of the field given by its position in its
+// set, so every set in the format must fit in the field, and a code can have
+// one character fewer than the size of the field.  The parity characters use
+// as many of the characters of the 'x' set as the field has symbols.  Formats
+// with words can't have parity characters.  ErrPolicySet is returned while a
+// policy is set.
+func (cf *CodeFactory) Correct(code string) (string, []Correction, error) {
+	fixes := []Correction{}
+	if cf.policy != nil {
+		return "", fixes, ErrPolicySet
+	}
+
+	slots := cf.slots()
+	rs, err := cf.reedSolomon(slots)
+	if err != nil {
+		return "", fixes, err
+	}
+	if rs == nil {
+		return "", fixes, ErrNoParity
+	}
+
+	// read one character for each slot, without checking the sets
+	if !strings.HasPrefix(code, cf.prefix) || !strings.HasSuffix(code, cf.suffix) ||
+		len(code) < len(cf.prefix)+len(cf.suffix) {
+		return "", fixes, ErrInvalidCode
+	}
+	chars := []rune(code[len(cf.prefix) : len(code)-len(cf.suffix)])
+	if len(chars) != len(slots) {
+		return "", fixes, ErrInvalidCode
+	}
+	word := make([]int, len(rs.pos))
+	for k, j := range rs.pos {
+		if d := indexOf(slots[j].vals, string(chars[j])); d >= 0 {
+			word[k] = d
+		}
+	}
+	for j, s := range slots {
+		if s.verb == 0 && string(chars[j]) != s.lit {
+			return "", fixes, ErrInvalidCode
+		}
+	}
+
+	if !rs.correct(word) {
+		return "", fixes, ErrUncorrectable
+	}
+
+	offset := len([]rune(cf.prefix))
+	for k, j := range rs.pos {
+		if word[k] >= len(slots[j].vals) {
+			return "", []Correction{}, ErrUncorrectable
+		}
+		if v := slots[j].vals[word[k]]; v != string(chars[j]) {
+			fixes = append(fixes, Correction{Pos: offset + j, From: string(chars[j]), To: v})
+			chars[j] = []rune(v)[0]
+		}
+	}
+	return cf.prefix + string(chars) + cf.suffix, fixes, nil
+}
+
+// galois is the finite field GF(q) for a prime q, or a power of 2, with
+// tables of the powers of a primitive element.
+type galois struct {
+	q      int
+	binary bool
+	exp    []int // exp[i] is the primitive element to the power i, for i < 2(q-1)
+	log    []int
+}
+
+// fieldSize returns the largest prime or power of 2 that is no more than n,
+// or 0 if there is none.
+func fieldSize(n int) int {
+	for q := n; q >= 2; q-- {
+		if q&(q-1) == 0 && q < 1<<len(binaryPolys) {
+			return q
+		}
+		if isPrime(q) {
+			return q
+		}
+	}
+	return 0
+}
+
+func isPrime(n int) bool {
+	for d := 2; d*d <= n; d++ {
+		if n%d == 0 {
+			return false
+		}
+	}
+	return n >= 2
+}
+
+func newGalois(q int) *galois {
+	g := &galois{q: q, binary: q&(q-1) == 0, log: make([]int, q)}
+
+	// find a primitive element, which has q-1 distinct powers
+	alpha, poly := 2, 0
+	if g.binary {
+		poly = binaryPolys[bitLen(q)-1]
+	}
+	for {
+		g.exp = g.exp[:0]
+		x := 1
+		for i := 0; i < q-1; i++ {
+			g.exp = append(g.exp, x)
+			if g.binary {
+				x <<= 1
+				if x >= q {
+					x ^= poly
+				}
+			} else {
+				x = x * alpha % q
+			}
+			if x == 1 && i < q-2 {
+				break
+			}
+		}
+		if len(g.exp) == q-1 || g.binary {
+			break
+		}
+		alpha++
+	}
+	for i, x := range g.exp {
+		g.log[x] = i
+	}
+	g.exp = append(g.exp, g.exp...)
+	return g
+}
+
+func bitLen(n int) int {
+	b := 0
+	for ; n > 0; n >>= 1 {
+		b++
+	}
+	return b
+}
+
+func (g *galois) add(a, b int) int {
+	if g.binary {
+		return a ^ b
+	}
+	return (a + b) % g.q
+}
+
+func (g *galois) sub(a, b int) int {
+	if g.binary {
+		return a ^ b
+	}
+	return (a - b + g.q) % g.q
+}
+
+func (g *galois) mul(a, b int) int {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return g.exp[g.log[a]+g.log[b]]
+}
+
+func (g *galois) div(a, b int) int {
+	if a == 0 {
+		return 0
+	}
+	return g.exp[g.log[a]-g.log[b]+g.q-1]
+}
+
+// pow returns the primitive element to the power n.
+func (g *galois) pow(n int) int {
+	n %= g.q - 1
+	if n < 0 {
+		n += g.q - 1
+	}
+	return g.exp[n]
+}
+
+// scale returns a added to itself n times.
+func (g *galois) scale(a, n int) int {
+	if g.binary {
+		return a * (n % 2)
+	}
+	return a * (n % g.q) % g.q
+}
+
+// eval returns the value of the polynomial p, lowest power first, at x.
+func (g *galois) eval(p []int, x int) int {
+	y := 0
+	for i := len(p) - 1; i >= 0; i-- {
+		y = g.add(g.mul(y, x), p[i])
+	}
+	return y
+}
+
+// reedSolomon holds the Reed-Solomon code for the format.  The codeword is
+// made up of the code characters in order, followed by the parity characters,
+// with the first symbol holding the highest power.
+type reedSolomon struct {
+	g   *galois
+	pos []int // the slot of each symbol of the codeword
+	n   int   // number of parity symbols
+	gen []int // generator polynomial, with the highest power first
+}
+
+// reedSolomon returns the Reed-Solomon code for the slots, or nil if the
+// format has no parity characters.
+func (cf *CodeFactory) reedSolomon(slots []slot) (*reedSolomon, error) {
+	rs := &reedSolomon{}
+	parity := []int{}
+	for j, s := range slots {
+		switch {
+		case s.verb == 'r':
+			parity = append(parity, j)
+		case s.verb != 0:
+			rs.pos = append(rs.pos, j)
+		}
+	}
+	if len(parity) == 0 {
+		return nil, nil
+	}
+	rs.pos = append(rs.pos, parity...)
+	rs.n = len(parity)
+
+	q := fieldSize(len([]rune(cf.set('x'))))
+	if q == 0 || len(rs.pos) > q-1 {
+		return nil, ErrNoField
+	}
+	for _, j := range rs.pos {
+		if len(slots[j].vals) > q || slots[j].verb == 'b' {
+			return nil, ErrNoField
+		}
+	}
+	rs.g = newGalois(q)
+
+	// the generator has the roots a^1 to a^n
+	rs.gen = []int{1}
+	for i := 1; i <= rs.n; i++ {
+		next := make([]int, len(rs.gen)+1)
+		for k, c := range rs.gen {
+			next[k] = rs.g.add(next[k], c)
+			next[k+1] = rs.g.sub(next[k+1], rs.g.mul(c, rs.g.pow(i)))
+		}
+		rs.gen = next
+	}
+	return rs, nil
+}
+
+// encode fills the parity slots of body from the other code characters.
+func (rs *reedSolomon) encode(slots []slot, body []string) {
+	k := len(rs.pos) - rs.n
+	word := make([]int, len(rs.pos))
+	for i, j := range rs.pos[:k] {
+		word[i] = indexOf(slots[j].vals, body[j])
+	}
+
+	// the remainder of the division by the generator is left in the parity
+	// symbols, and subtracted to make the codeword a multiple of it
+	for i := 0; i < k; i++ {
+		c := word[i]
+		if c == 0 {
+			continue
+		}
+		for l := 1; l < len(rs.gen); l++ {
+			word[i+l] = rs.g.sub(word[i+l], rs.g.mul(rs.gen[l], c))
+		}
+	}
+	for i, j := range rs.pos[k:] {
+		body[j] = slots[j].vals[rs.g.sub(0, word[k+i])]
+	}
+}
+
+// correct corrects the errors in the codeword in place, and returns false if
+// there are too many to correct.
+func (rs *reedSolomon) correct(word []int) bool {
+	g := rs.g
+	n := len(word)
+
+	// the polynomial of the word, with the lowest power first
+	poly := make([]int, n)
+	for i, c := range word {
+		poly[n-1-i] = c
+	}
+
+	syn := rs.syndromes(poly)
+	if isZero(syn) {
+		return true
+	}
+
+	// Berlekamp-Massey finds the error locator polynomial
+	locator, prev := []int{1}, []int{1}
+	errs, m, b := 0, 1, 1
+	for i := 0; i < rs.n; i++ {
+		d := syn[i]
+		for l := 1; l <= errs && l < len(locator); l++ {
+			d = g.add(d, g.mul(locator[l], syn[i-l]))
+		}
+		if d == 0 {
+			m++
+			continue
+		}
+		next := append([]int{}, locator...)
+		coef := g.div(d, b)
+		for len(next) < len(prev)+m {
+			next = append(next, 0)
+		}
+		for l, c := range prev {
+			next[l+m] = g.sub(next[l+m], g.mul(coef, c))
+		}
+		if 2*errs <= i {
+			prev, errs, b, m = locator, i+1-errs, d, 1
+		} else {
+			m++
+		}
+		locator = next
+	}
+	if 2*errs > rs.n {
+		return false
+	}
+
+	// Chien search finds the positions whose inverse locators are roots
+	positions := []int{}
+	for i := 0; i < n; i++ {
+		if g.eval(locator, g.pow(-i)) == 0 {
+			positions = append(positions, i)
+		}
+	}
+	if len(positions) != errs {
+		return false
+	}
+
+	// Forney's algorithm finds the size of each error from the evaluator
+	// polynomial and the derivative of the locator
+	eval := make([]int, rs.n)
+	for i := range eval {
+		for l := 0; l <= i && l < len(locator); l++ {
+			eval[i] = g.add(eval[i], g.mul(locator[l], syn[i-l]))
+		}
+	}
+	deriv := make([]int, len(locator))
+	for l := 1; l < len(locator); l++ {
+		deriv[l-1] = g.scale(locator[l], l)
+	}
+	for _, i := range positions {
+		x := g.pow(-i)
+		den := g.eval(deriv, x)
+		if den == 0 {
+			return false
+		}
+		poly[i] = g.sub(poly[i], g.sub(0, g.div(g.eval(eval, x), den)))
+	}
+
+	for i := range word {
+		word[i] = poly[n-1-i]
+	}
+	return isZero(rs.syndromes(poly))
+}
+
+// check reports whether the parity characters of body match the other code
+// characters, as every syndrome of the codeword is 0.
+func (rs *reedSolomon) check(slots []slot, body []string) bool {
+	poly := make([]int, len(rs.pos))
+	for i, j := range rs.pos {
+		poly[len(poly)-1-i] = indexOf(slots[j].vals, body[j])
+	}
+	return isZero(rs.syndromes(poly))
+}
+
+// syndromes returns the values of the polynomial of a codeword, with the
+// lowest power first, at the roots of the generator.
+func (rs *reedSolomon) syndromes(poly []int) []int {
+	syn := make([]int, rs.n)
+	for i := range syn {
+		syn[i] = rs.g.eval(poly, rs.g.pow(i+1))
+	}
+	return syn
+}
+
+func isZero(p []int) bool {
+	for _, c := range p {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,196 @@
+package codefactory
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGalois(t *testing.T) {
+
+	Convey("Every field has a primitive element", t, func() {
+
+		for _, q := range []int{2, 3, 5, 7, 8, 16, 23, 32, 61, 64, 128} {
+			g := newGalois(q)
+
+			seen := map[int]bool{}
+			for i := 0; i < q-1; i++ {
+				seen[g.pow(i)] = true
+			}
+			So(len(seen), ShouldEqual, q-1)
+			So(seen[0], ShouldBeFalse)
+
+			for a := 1; a < q; a++ {
+				for b := 1; b < q; b++ {
+					So(g.div(g.mul(a, b), b), ShouldEqual, a)
+				}
+				So(g.sub(g.add(a, 1), 1), ShouldEqual, a)
+			}
+		}
+	})
+
+	Convey("Field sizes are primes or powers of 2", t, func() {
+		So(fieldSize(62), ShouldEqual, 61)
+		So(fieldSize(36), ShouldEqual, 32)
+		So(fieldSize(26), ShouldEqual, 23)
+		So(fieldSize(10), ShouldEqual, 8)
+		So(fieldSize(1), ShouldEqual, 0)
+	})
+}
+
+func TestCorrect(t *testing.T) {
+	var testCases = []struct {
+		desc   string
+		cf     *CodeFactory
+		format string
+		errors int
+	}{
+		{
+			desc:   "Crockford Base32",
+			cf:     NewCrockford(),
+			format: "xxxxxxxx-rrrr",
+			errors: 2,
+		},
+		{
+			desc:   "hex",
+			cf:     NewHex(),
+			format: "xxxxxxxxxrrrrrr",
+			errors: 3,
+		},
+		{
+			desc:   "binary field with mixed sets",
+			cf:     NewReadable(),
+			format: "dddd-llll-rrr",
+			errors: 1,
+		},
+		{
+			desc:   "prime field",
+			cf:     New(),
+			format: "pppppppp-rrrr",
+			errors: 2,
+		},
+		{
+			desc:   "prime field with mixed sets",
+			cf:     New(),
+			format: "dddd-llll-rrrrrr",
+			errors: 3,
+		},
+	}
+
+	for i, tt := range testCases {
+		Convey(fmt.Sprintf("Case # %d: %s", i, tt.desc), t, func() {
+
+			cf := tt.cf
+			So(cf.SetFormat(tt.format), ShouldBeNil)
+			cf.SetSeed(1)
+			rng := rand.New(rand.NewSource(1))
+
+			res, err := cf.Generate(200)
+			So(err, ShouldBeNil)
+
+			for _, code := range res {
+				So(cf.Validate(code), ShouldBeNil)
+
+				got, fixes, err := cf.Correct(code)
+				So(err, ShouldBeNil)
+				So(got, ShouldEqual, code)
+				So(fixes, ShouldBeEmpty)
+
+				// change up to `errors` code characters, sometimes to characters
+				// that aren't in their set
+				chars := []rune(code)
+				changed := map[int]bool{}
+				for len(changed) < 1+rng.Intn(tt.errors) {
+					p := rng.Intn(len(chars))
+					if chars[p] == '-' || changed[p] {
+						continue
+					}
+					c := []rune("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ!")[rng.Intn(37)]
+					if c == chars[p] {
+						continue
+					}
+					chars[p] = c
+					changed[p] = true
+				}
+
+				So(cf.Validate(string(chars)), ShouldNotBeNil)
+				got, fixes, err = cf.Correct(string(chars))
+				So(err, ShouldBeNil)
+				So(got, ShouldEqual, code)
+				So(len(fixes), ShouldEqual, len(changed))
+				for _, f := range fixes {
+					So(changed[f.Pos], ShouldBeTrue)
+					So(f.To, ShouldEqual, string([]rune(code)[f.Pos]))
+				}
+			}
+		})
+	}
+
+	Convey("Corrections report the characters changed", t, func() {
+
+		cf := NewHex()
+		cf.SetPrefix("#")
+		cf.SetFormat("xxxx-rr")
+
+		got, fixes, err := cf.Correct("#00a0-00")
+		So(err, ShouldBeNil)
+		So(got, ShouldEqual, "#0000-00")
+		So(fixes, ShouldResemble, []Correction{{Pos: 3, From: "a", To: "0"}})
+
+		_, _, err = cf.Correct("#0000-0")
+		So(err, ShouldEqual, ErrInvalidCode)
+	})
+
+	Convey("Validate checks the parity characters", t, func() {
+
+		cf := NewCrockford()
+		cf.SetFormat("xxxxxxxx-rr")
+		So(cf.Validate("00000000-00"), ShouldBeNil)
+		So(cf.Validate("00000000-01"), ShouldEqual, ErrBadParity)
+		So(cf.Validate("00000001-00"), ShouldEqual, ErrBadParity)
+
+		cf = New()
+		cf.SetFormat("pppp-rr")
+		res, err := cf.Generate(1)
+		So(err, ShouldBeNil)
+		So(cf.Validate(res[0]), ShouldBeNil)
+		last := "0"
+		if res[0][6] == '0' {
+			last = "1"
+		}
+		So(cf.Validate(res[0][:6]+last), ShouldEqual, ErrBadParity)
+
+		cf.SetFormat("xxxx-rr")
+		So(cf.Validate("0000-00"), ShouldEqual, ErrNoField)
+	})
+
+	Convey("Too many errors can't be corrected", t, func() {
+
+		cf := NewCrockford()
+		cf.SetFormat("xxxxxxxx-rr")
+
+		_, fixes, err := cf.Correct("01200000-00")
+		So(err, ShouldEqual, ErrUncorrectable)
+		So(fixes, ShouldBeEmpty)
+	})
+
+	Convey("Formats that can't hold parity are rejected", t, func() {
+
+		cf := New()
+		cf.Exclude(defaultUppercase + defaultLowercase)
+		cf.SetFormat("dddd-rr")
+		_, err := cf.Generate(10)
+		So(err, ShouldEqual, ErrNoField)
+
+		cf = NewHex()
+		cf.SetFormat("xxxxxxxxxxxxxxrr")
+		_, err = cf.Generate(10)
+		So(err, ShouldEqual, ErrNoField)
+
+		cf = NewHex()
+		_, _, err = cf.Correct("01234567")
+		So(err, ShouldEqual, ErrNoParity)
+	})
+}
@@ -0,0 +1,270 @@
+package codefactory
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Policy describes codes, such as temporary passwords, that are made up of
+// Length characters from the number, uppercase, lowercase, and custom sets of
+// the CodeFactory, in any order, with at least a minimum number from each set.
+type Policy struct {
+	Length int // number of code characters
+	Digits int // minimum number of digits
+	Upper  int // minimum number of uppercase letters
+	Lower  int // minimum number of lowercase letters
+	Custom int // minimum number of characters from the custom set
+}
+
+// composition is a way of splitting the code characters among the sets of a
+// policy.
+type composition struct {
+	counts []int    // number of characters from each set
+	weight *big.Int // number of codes with these counts
+}
+
+// SetPolicy switches the CodeFactory to generating codes by the policy `p`
+// instead of by the format.  Every code that meets the policy is equally
+// likely, and MaxCodes and Entropy count the codes that meet it exactly.
+//
+// While a policy is set, Generate, MaxCodes, Entropy, Feasible, Validate, and
+// ECMAScriptPattern follow the policy.  The prefix, suffix, blocklist, reserved
+// codes, retry policy, and seed still apply.  Methods that only work with the
+// format, which are Normalize, Decode, Time, Correct, Iterator, Shard, Vanity,
+// Regexp, EncodeID, and DecodeID, return ErrPolicySet.  The zero Policy
+// switches back to the format.
+func (cf *CodeFactory) SetPolicy(p Policy) error {
+	if p == (Policy{}) {
+		cf.policy = nil
+		return nil
+	}
+	if p.Length < 1 || p.Digits < 0 || p.Upper < 0 || p.Lower < 0 || p.Custom < 0 ||
+		p.Digits+p.Upper+p.Lower+p.Custom > p.Length {
+		return ErrInvalidPolicy
+	}
+	cf.policy = &p
+	return nil
+}
+
+// policySets returns the sets of the policy along with the minimum number of
+// characters from each.
+func (cf *CodeFactory) policySets() ([]string, []int) {
+	p := cf.policy
+	return []string{cf.num, cf.upper, cf.lower, cf.custom},
+		[]int{p.Digits, p.Upper, p.Lower, p.Custom}
+}
+
+// compositions returns every way of splitting the code characters of the
+// policy among its sets, along with the total number of codes.
+func (cf *CodeFactory) compositions() ([]composition, *big.Int) {
+	sets, mins := cf.policySets()
+	res := []composition{}
+	total := new(big.Int)
+
+	counts := make([]int, len(sets))
+	var split func(i, left int)
+	split = func(i, left int) {
+		if i == len(sets)-1 {
+			counts[i] = left
+			if left < mins[i] || (left > 0 && sets[i] == "") {
+				return
+			}
+			c := composition{counts: append([]int{}, counts...), weight: policyWeight(sets, counts)}
+			total.Add(total, c.weight)
+			res = append(res, c)
+			return
+		}
+		for k := mins[i]; k <= left; k++ {
+			if k > 0 && sets[i] == "" {
+				return
+			}
+			counts[i] = k
+			split(i+1, left-k)
+		}
+	}
+	split(0, cf.policy.Length)
+	return res, total
+}
+
+// policyWeight returns the number of codes with the given number of
+// characters from each set: the ways of placing them, times the ways of
+// choosing each character.
+func policyWeight(sets []string, counts []int) *big.Int {
+	w := big.NewInt(1)
+	n := 0
+	for i, k := range counts {
+		n += k
+		w.Mul(w, new(big.Int).Binomial(int64(n), int64(k)))
+		size := big.NewInt(int64(len([]rune(sets[i]))))
+		w.Mul(w, size.Exp(size, big.NewInt(int64(k)), nil))
+	}
+	return w
+}
+
+// policyMaxCodes returns the number of codes that meet the policy, limited in
+// the same way as MaxCodes.
+func (cf *CodeFactory) policyMaxCodes() int64 {
+	if max := cf.policySpace(); max < maxNumCodes {
+		return max
+	}
+	return maxNumCodes
+}
+
+// policySpace returns the number of codes that meet the policy and aren't
+// reserved, limited to math.MaxInt64 instead of maxNumCodes.
+func (cf *CodeFactory) policySpace() int64 {
+	_, total := cf.compositions()
+	total.Sub(total, big.NewInt(cf.reservedCount()))
+	if total.Cmp(big.NewInt(1)) <= 0 {
+		return 0
+	} else if !total.IsInt64() {
+		return math.MaxInt64
+	}
+	return total.Int64()
+}
+
+// policyEntropy returns the base 2 logarithm of the number of codes that
+// meet the policy.
+func (cf *CodeFactory) policyEntropy() float64 {
+	_, total := cf.compositions()
+	if total.Sign() == 0 {
+		return 0
+	}
+	mant := new(big.Float)
+	exp := new(big.Float).SetInt(total).MantExp(mant)
+	m, _ := mant.Float64()
+	return float64(exp) + math.Log2(m)
+}
+
+// checkPolicy returns an error if `num` codes can't be generated by the
+// policy.
+func (cf *CodeFactory) checkPolicy(num int) error {
+	sets, mins := cf.policySets()
+	for i, set := range sets {
+		if mins[i] > 0 && set == "" {
+			return ErrNoCharacters
+		}
+	}
+
+	// the custom set must not share characters with the others, so that
+	// each character of a code counts towards one set
+	if strings.ContainsAny(cf.custom, cf.num+cf.upper+cf.lower) {
+		return ErrInvalidPolicy
+	}
+
+	maxCodes := cf.policyMaxCodes()
+	if maxCodes == 0 {
+		return ErrNoCharacters
+	} else if int64(num) > maxCodes {
+		return &CountError{Requested: int64(num), Achievable: maxCodes}
+	}
+	return nil
+}
+
+// generatePolicy generates `num` codes by the policy.  A code is drawn by
+// picking how many characters come from each set, in proportion to the number
+// of codes with those counts, then shuffling their positions and picking each
+// character from its set.
+func (cf *CodeFactory) generatePolicy(ctx context.Context, num int, rep *Report) (map[string]bool, error) {
+	res := map[string]bool{}
+
+	sets, _ := cf.policySets()
+	runes := make([][]rune, len(sets))
+	for i, set := range sets {
+		runes[i] = []rune(set)
+	}
+	comps, total := cf.compositions()
+
+	// cum[i] is the number of codes in the first i+1 compositions
+	cum := make([]*big.Int, len(comps))
+	sum := new(big.Int)
+	for i, c := range comps {
+		sum.Add(sum, c.weight)
+		cum[i] = new(big.Int).Set(sum)
+	}
+
+	rng := cf.rng()
+	maxRetries := cf.maxRetries(num, cf.policySpace())
+	rep.RetryBudget = maxRetries
+	start := time.Now()
+
+	body := make([]string, cf.policy.Length)
+	pos := make([]int, 0, cf.policy.Length)
+	for i := 1; i <= num; i++ {
+
+		if err := ctx.Err(); err != nil {
+			return map[string]bool{}, err
+		}
+
+		x := randomBig(rng, total)
+		c := comps[sort.Search(len(cum), func(j int) bool { return cum[j].Cmp(x) > 0 })]
+
+		// the set of each position, shuffled
+		pos = pos[:0]
+		for set, k := range c.counts {
+			for ; k > 0; k-- {
+				pos = append(pos, set)
+			}
+		}
+		for j := len(pos) - 1; j > 0; j-- {
+			l := rng.Intn(j + 1)
+			pos[j], pos[l] = pos[l], pos[j]
+		}
+		for j, set := range pos {
+			body[j] = string(runes[set][rng.Intn(len(runes[set]))])
+		}
+
+		r := cf.prefix + strings.Join(body, "") + cf.suffix
+		if res[r] || cf.reserved[r] || cf.blocked(body) {
+			i-- // generate a new code
+			rep.Duplicates++
+			if rep.Duplicates > maxRetries {
+				return map[string]bool{}, ErrMaxRetriesExceeded
+			}
+			continue
+		}
+		res[r] = true
+
+		// report progress now and then
+		if i%progressInterval == 0 {
+			cf.report(start, i, num, rep.Duplicates)
+		}
+	}
+	cf.report(start, num, num, rep.Duplicates)
+	return res, nil
+}
+
+// validatePolicy checks that the code meets the policy.
+func (cf *CodeFactory) validatePolicy(code string) error {
+	if len(code) < len(cf.prefix)+len(cf.suffix) ||
+		!strings.HasPrefix(code, cf.prefix) || !strings.HasSuffix(code, cf.suffix) {
+		return ErrInvalidCode
+	}
+	chars := []rune(code[len(cf.prefix) : len(code)-len(cf.suffix)])
+	if len(chars) != cf.policy.Length {
+		return ErrInvalidCode
+	}
+
+	sets, mins := cf.policySets()
+	counts := make([]int, len(sets))
+	for _, v := range chars {
+		i := 0
+		for i < len(sets) && !isIncludedIn(sets[i], v) {
+			i++
+		}
+		if i == len(sets) {
+			return ErrInvalidCode
+		}
+		counts[i]++
+	}
+	for i, k := range counts {
+		if k < mins[i] {
+			return ErrInvalidCode
+		}
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package codefactory
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// smallPolicy returns a CodeFactory with tiny sets, and the number of codes
+// that meet its policy, counted one by one.
+func smallPolicy() (*CodeFactory, int64) {
+	cf := New()
+	cf.Exclude("23456789CDEFGHIJKLMNOPQRSTUVWXYZ" + defaultLowercase)
+	cf.SetCustom("!")
+	cf.SetPolicy(Policy{Length: 4, Digits: 1, Upper: 1, Custom: 1})
+
+	chars := "01AB!"
+	n := int64(0)
+	for i := 0; i < 625; i++ {
+		code := ""
+		for j, k := 0, i; j < 4; j, k = j+1, k/5 {
+			code += string(chars[k%5])
+		}
+		if strings.ContainsAny(code, "01") && strings.ContainsAny(code, "AB") && strings.Contains(code, "!") {
+			n++
+		}
+	}
+	return cf, n
+}
+
+func TestPolicy(t *testing.T) {
+
+	Convey("The codes that meet a policy are counted exactly", t, func() {
+
+		cf, n := smallPolicy()
+		So(cf.MaxCodes(), ShouldEqual, n)
+		So(cf.Entropy(), ShouldAlmostEqual, math.Log2(float64(n)))
+
+		cf.SetSeed(1)
+		res, err := cf.Generate(int(n))
+		So(err, ShouldBeNil)
+		So(len(res), ShouldEqual, n)
+		for _, code := range res {
+			So(cf.Validate(code), ShouldBeNil)
+		}
+	})
+
+	Convey("Codes are drawn uniformly from the codes that meet a policy", t, func() {
+
+		cf := New()
+		cf.SetCustom("!@#$%")
+		cf.SetSeed(1)
+		So(cf.SetPolicy(Policy{Length: 8, Digits: 2, Upper: 2, Custom: 2}), ShouldBeNil)
+
+		// the share of codes with exactly two custom characters
+		comps, total := cf.compositions()
+		want := new(big.Int)
+		for _, c := range comps {
+			if c.counts[3] == 2 {
+				want.Add(want, c.weight)
+			}
+		}
+		share, _ := new(big.Rat).SetFrac(want, total).Float64()
+
+		res, err := cf.Generate(20000)
+		So(err, ShouldBeNil)
+		got := 0
+		for _, code := range res {
+			So(cf.Validate(code), ShouldBeNil)
+			if strings.Count(code, "!")+strings.Count(code, "@")+strings.Count(code, "#")+
+				strings.Count(code, "$")+strings.Count(code, "%") == 2 {
+				got++
+			}
+		}
+		So(float64(got)/20000, ShouldAlmostEqual, share, 0.02)
+	})
+
+	Convey("Invalid policies are rejected", t, func() {
+
+		cf := New()
+		for _, p := range []Policy{
+			{Length: -1},
+			{Length: 4, Digits: -1},
+			{Length: 4, Digits: 2, Upper: 2, Lower: 1},
+			{Digits: 1},
+		} {
+			So(cf.SetPolicy(p), ShouldEqual, ErrInvalidPolicy)
+		}
+
+		So(cf.SetPolicy(Policy{Length: 8, Custom: 1}), ShouldBeNil)
+		_, err := cf.Generate(1)
+		So(err, ShouldEqual, ErrNoCharacters)
+
+		cf.SetCustom("a!")
+		_, err = cf.Generate(1)
+		So(err, ShouldEqual, ErrInvalidPolicy)
+
+		_, err = cf.Shard(0, 2)
+		So(err, ShouldEqual, ErrPolicySet)
+	})
+
+	Convey("Methods that only work with the format refuse a policy", t, func() {
+
+		cf := New()
+		cf.SetFormat("dddd")
+		cf.SetSalt([]byte("pepper"))
+		code, _ := cf.EncodeID(42)
+		So(cf.SetPolicy(Policy{Length: 4, Digits: 1}), ShouldBeNil)
+		res, err := cf.Generate(1)
+		So(err, ShouldBeNil)
+
+		_, err = cf.Decode(res[0])
+		So(err, ShouldEqual, ErrPolicySet)
+		_, err = cf.Time(res[0])
+		So(err, ShouldEqual, ErrPolicySet)
+		_, _, err = cf.Correct(res[0])
+		So(err, ShouldEqual, ErrPolicySet)
+		_, err = cf.Shard(0, 2)
+		So(err, ShouldEqual, ErrPolicySet)
+		_, err = cf.Vanity("7", 1)
+		So(err, ShouldEqual, ErrPolicySet)
+		_, err = cf.Regexp()
+		So(err, ShouldEqual, ErrPolicySet)
+		_, err = cf.Iterator("")
+		So(err, ShouldEqual, ErrPolicySet)
+		_, err = cf.EncodeID(42)
+		So(err, ShouldEqual, ErrPolicySet)
+		_, err = cf.DecodeID(code)
+		So(err, ShouldEqual, ErrPolicySet)
+		_, err = cf.Normalize(code)
+		So(err, ShouldEqual, ErrPolicySet)
+
+		cf.SetPolicy(Policy{})
+		it, err := cf.Iterator("")
+		So(err, ShouldBeNil)
+		So(it.Next(), ShouldBeTrue)
+		id, err := cf.DecodeID(code)
+		So(err, ShouldBeNil)
+		So(id, ShouldEqual, 42)
+		_, err = cf.Normalize(code)
+		So(err, ShouldBeNil)
+	})
+
+	Convey("The zero policy switches back to the format", t, func() {
+
+		cf := New()
+		cf.SetFormat("dd")
+		fp := cf.Fingerprint()
+		cf.SetPolicy(Policy{Length: 8})
+		So(cf.MaxCodes(), ShouldEqual, maxNumCodes)
+		So(cf.Fingerprint(), ShouldNotEqual, fp)
+
+		So(cf.SetPolicy(Policy{}), ShouldBeNil)
+		So(cf.MaxCodes(), ShouldEqual, 100)
+		So(cf.Fingerprint(), ShouldEqual, fp)
+	})
+}
+
+func TestValidatePolicy(t *testing.T) {
+	var testCases = []struct {
+		desc string
+		code string
+		err  error
+	}{
+		{
+			desc: "meets the policy",
+			code: "pw-aB3!x9Zq",
+		},
+		{
+			desc: "classes in any order",
+			code: "pw-!!ZZ00aa",
+		},
+		{
+			desc: "too few digits",
+			code: "pw-aB3!xQZq",
+			err:  ErrInvalidCode,
+		},
+		{
+			desc: "no custom character",
+			code: "pw-aB3qx9Zq",
+			err:  ErrInvalidCode,
+		},
+		{
+			desc: "character outside the sets",
+			code: "pw-aB3?x9Zq",
+			err:  ErrInvalidCode,
+		},
+		{
+			desc: "too short",
+			code: "pw-aB3!x9Z",
+			err:  ErrInvalidCode,
+		},
+		{
+			desc: "missing prefix",
+			code: "aB3!x9Zq",
+			err:  ErrInvalidCode,
+		},
+	}
+
+	for i, tt := range testCases {
+		Convey(fmt.Sprintf("Case # %d: %s", i, tt.desc), t, func() {
+
+			cf := New()
+			cf.SetPrefix("pw-")
+			cf.SetCustom("!@#")
+			cf.SetPolicy(Policy{Length: 8, Digits: 2, Upper: 1, Custom: 1})
+			So(cf.Validate(tt.code), ShouldEqual, tt.err)
+		})
+	}
+}
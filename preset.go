@@ -0,0 +1,87 @@
+package codefactory
+
+const (
+	crockfordExclude = "ILOU"
+	base58Exclude    = "0IOl"
+	rfc4648Alphabet  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+	zBase32Alphabet  = "ybndrfg8ejkmcpqxot1uwisza345h769"
+	hexLowercase     = "abcdef"
+	presetFormat     = "xxxxxxxx"
+	presetCustom     = "cccccccc"
+)
+
+// NewCrockford generates a CodeFactory for Crockford's Base32 alphabet,
+// "0123456789ABCDEFGHJKMNPQRSTVWXYZ", with a default format of 8 characters.
+//
+// Normalize accepts lowercase letters, and decodes I and L as 1, and O as 0,
+// as the Crockford specification requires.
+func NewCrockford() *CodeFactory {
+	cf := New()
+	cf.lower = ""
+	cf.Exclude(crockfordExclude)
+	cf.format = presetFormat
+	cf.aliases = map[rune]rune{
+		'I': '1', 'i': '1', 'L': '1', 'l': '1',
+		'O': '0', 'o': '0',
+	}
+	return cf
+}
+
+// NewBase32 generates a CodeFactory for the RFC 4648 Base32 alphabet,
+// "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567", with a default format of 8 characters.
+//
+// The alphabet is held in the custom set, so formats should use 'c'.  Normalize
+// accepts lowercase letters, but doesn't map any look-alikes.
+func NewBase32() *CodeFactory {
+	return newAlphabet(rfc4648Alphabet)
+}
+
+// NewZBase32 generates a CodeFactory for the z-base-32 alphabet,
+// "ybndrfg8ejkmcpqxot1uwisza345h769", with a default format of 8 characters.
+//
+// The alphabet is held in the custom set, so formats should use 'c'.  Normalize
+// accepts uppercase letters, but doesn't map any look-alikes.
+func NewZBase32() *CodeFactory {
+	return newAlphabet(zBase32Alphabet)
+}
+
+// NewBase58 generates a CodeFactory for the Bitcoin Base58 alphabet,
+// "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz", with a default
+// format of 8 characters.
+//
+// Base58 is case sensitive, so Normalize neither folds case nor maps any
+// look-alikes.
+func NewBase58() *CodeFactory {
+	cf := New()
+	cf.Exclude(base58Exclude)
+	cf.format = presetFormat
+	cf.aliases = map[rune]rune{}
+	cf.strictCase = true
+	return cf
+}
+
+// NewHex generates a CodeFactory for lowercase hexadecimal,
+// "0123456789abcdef", with a default format of 8 characters.
+//
+// Normalize accepts uppercase letters, but doesn't map any look-alikes.
+func NewHex() *CodeFactory {
+	cf := New()
+	cf.lower = hexLowercase
+	cf.upper = ""
+	cf.format = presetFormat
+	cf.aliases = map[rune]rune{}
+	return cf
+}
+
+// newAlphabet generates a CodeFactory that only uses the alphabet as its custom
+// set.
+func newAlphabet(alphabet string) *CodeFactory {
+	cf := New()
+	cf.num = ""
+	cf.lower = ""
+	cf.upper = ""
+	cf.custom = alphabet
+	cf.format = presetCustom
+	cf.aliases = map[rune]rune{}
+	return cf
+}
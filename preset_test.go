@@ -0,0 +1,89 @@
+package codefactory
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPresets(t *testing.T) {
+	var testCases = []struct {
+		desc     string
+		cf       *CodeFactory
+		verb     rune
+		wantSet  string
+		input    string
+		want     string
+		badInput string
+	}{
+		{
+			desc:     "Crockford Base32",
+			cf:       NewCrockford(),
+			verb:     'x',
+			wantSet:  "0123456789ABCDEFGHJKMNPQRSTVWXYZ",
+			input:    "oi-lz 3abv",
+			want:     "011Z3ABV",
+			badInput: "011Z3ABu!",
+		},
+		{
+			desc:     "RFC 4648 Base32",
+			cf:       NewBase32(),
+			verb:     'c',
+			wantSet:  "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567",
+			input:    "mfrg-gzdf",
+			want:     "MFRGGZDF",
+			badInput: "MFRGGZD0",
+		},
+		{
+			desc:     "z-base-32",
+			cf:       NewZBase32(),
+			verb:     'c',
+			wantSet:  "ybndrfg8ejkmcpqxot1uwisza345h769",
+			input:    "YBND RFG8",
+			want:     "ybndrfg8",
+			badInput: "ybndrfgl",
+		},
+		{
+			desc:     "Base58",
+			cf:       NewBase58(),
+			verb:     'x',
+			wantSet:  "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz",
+			input:    "3yQ5 2xZa",
+			want:     "3yQ52xZa",
+			badInput: "3yQ52xZI",
+		},
+		{
+			desc:     "hexadecimal",
+			cf:       NewHex(),
+			verb:     'x',
+			wantSet:  "0123456789abcdef",
+			input:    "DEAD-beef",
+			want:     "deadbeef",
+			badInput: "deadbeeO",
+		},
+	}
+
+	for i, tt := range testCases {
+		Convey(fmt.Sprintf("Case # %d: %s", i, tt.desc), t, func() {
+
+			So(tt.cf.set(tt.verb), ShouldEqual, tt.wantSet)
+
+			res, err := tt.cf.Normalize(tt.input)
+			So(err, ShouldBeNil)
+			So(res, ShouldEqual, tt.want)
+
+			_, err = tt.cf.Normalize(tt.badInput)
+			So(err, ShouldEqual, ErrInvalidCode)
+
+			codes, err := tt.cf.Generate(10)
+			So(err, ShouldBeNil)
+			for _, code := range codes {
+				So(len(code), ShouldEqual, 8)
+				for _, v := range code {
+					So(isIncludedIn(tt.wantSet, v), ShouldBeTrue)
+				}
+			}
+		})
+	}
+}
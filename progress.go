@@ -0,0 +1,37 @@
+package codefactory
+
+import "time"
+
+// Progress reports how far a call to Generate has got.
+type Progress struct {
+	Generated int           // codes generated so far
+	Total     int           // codes requested
+	Retries   int           // duplicate codes that had to be generated again
+	Remaining time.Duration // estimated time until all codes are generated
+}
+
+// SetProgress sets a function that is called with the progress of Generate
+// after every 16,384 codes, and once more when all the codes have been
+// generated.  It is called on the goroutine running Generate, so it should
+// return quickly.  A nil function stops the reporting.
+func (cf *CodeFactory) SetProgress(f func(Progress)) {
+	cf.progress = f
+}
+
+// report calls the progress function, if there is one, estimating the time
+// remaining from the rate so far.
+func (cf *CodeFactory) report(start time.Time, generated, total, retries int) {
+	if cf.progress == nil {
+		return
+	}
+	p := Progress{
+		Generated: generated,
+		Total:     total,
+		Retries:   retries,
+	}
+	if generated > 0 {
+		elapsed := time.Since(start)
+		p.Remaining = elapsed * time.Duration(total-generated) / time.Duration(generated)
+	}
+	cf.progress(p)
+}
@@ -0,0 +1,129 @@
+package codefactory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGenerateContext(t *testing.T) {
+
+	Convey("Generate stops when the context is cancelled", t, func() {
+
+		cf := New()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		res, err := cf.GenerateContext(ctx, 100)
+
+		So(err, ShouldEqual, context.Canceled)
+		So(res, ShouldResemble, []string{})
+	})
+
+	Convey("Generate stops when the deadline passes", t, func() {
+
+		cf := New()
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		res, err := cf.GenerateContext(ctx, 20000)
+
+		So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+		So(res, ShouldResemble, []string{})
+	})
+
+	var testCases = []struct {
+		desc    string
+		format  string
+		num     int
+		minDist int
+	}{
+		{
+			desc:   "cancelled while drawing codes at random",
+			format: "xxxxxxxxxx",
+			num:    5000000,
+		},
+		{
+			desc:   "cancelled while drawing codes without replacement",
+			format: "ddddddd",
+			num:    9000000,
+		},
+		{
+			desc:    "cancelled while drawing codes far apart",
+			format:  "xxxxxx",
+			num:     20000,
+			minDist: 3,
+		},
+	}
+
+	for i, tt := range testCases {
+		Convey(fmt.Sprintf("Case # %d: Generate stops promptly when %s", i, tt.desc), t, func() {
+
+			cf := New()
+			cf.SetFormat(tt.format)
+			if tt.minDist > 0 {
+				cf.SetMinDistance(tt.minDist, DamerauLevenshtein)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(20*time.Millisecond, cancel)
+
+			start := time.Now()
+			res, err := cf.GenerateContext(ctx, tt.num)
+
+			So(err, ShouldEqual, context.Canceled)
+			So(res, ShouldResemble, []string{})
+			So(time.Since(start), ShouldBeLessThan, time.Second)
+		})
+	}
+
+	Convey("Generate runs to the end if the context isn't cancelled", t, func() {
+
+		cf := New()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		res, err := cf.GenerateContext(ctx, 20000)
+
+		So(err, ShouldBeNil)
+		So(len(res), ShouldEqual, 20000)
+	})
+}
+
+func TestSetProgress(t *testing.T) {
+
+	Convey("Progress is reported while generating", t, func() {
+
+		cf := New()
+		cf.SetFormat("xxxxxx")
+		reports := []Progress{}
+		cf.SetProgress(func(p Progress) {
+			reports = append(reports, p)
+		})
+
+		_, err := cf.Generate(50000)
+		So(err, ShouldBeNil)
+
+		So(len(reports), ShouldEqual, 4)
+		So(reports[0].Generated, ShouldEqual, progressInterval)
+		So(reports[1].Generated, ShouldEqual, 2*progressInterval)
+		So(reports[2].Generated, ShouldEqual, 3*progressInterval)
+		So(reports[3].Generated, ShouldEqual, 50000)
+		So(reports[3].Remaining, ShouldEqual, 0)
+		for _, p := range reports {
+			So(p.Total, ShouldEqual, 50000)
+		}
+
+		Convey("and stops when the function is cleared", func() {
+			reports = []Progress{}
+			cf.SetProgress(nil)
+
+			_, err := cf.Generate(50000)
+			So(err, ShouldBeNil)
+			So(len(reports), ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,140 @@
+package codefactory
+
+import (
+	"strings"
+	"unicode"
+)
+
+// vowels are the Latin1 vowels, in lowercase and uppercase.
+const vowels = "aeiouàáâãäåæèéêëìíîïòóôõöøùúûüAEIOUÀÁÂÃÄÅÆÈÉÊËÌÍÎÏÒÓÔÕÖØÙÚÛÜ"
+
+// SetBlocklist sets words that generated codes must not contain, such as
+// offensive words that pronounceable codes may spell out.  The words are
+// matched against the code, without its prefix and suffix, ignoring case.
+// Codes that contain one are discarded like duplicates, and are skipped by an
+// Iterator.  Empty words are ignored.
+//
+// MaxCodes leaves out the blocked codes if there are at most 65,536 ways to
+// fill the random characters of the format.  For larger formats it can't tell
+// how many codes are blocked, and counts them as well.
+func (cf *CodeFactory) SetBlocklist(words []string) {
+	cf.blocklist = []string{}
+	for _, w := range words {
+		if w != "" {
+			cf.blocklist = append(cf.blocklist, strings.ToLower(w))
+		}
+	}
+}
+
+// blocked reports whether the body of a code contains a word on the blocklist.
+func (cf *CodeFactory) blocked(body []string) bool {
+	if len(cf.blocklist) == 0 {
+		return false
+	}
+	code := strings.ToLower(strings.Join(body, ""))
+	for _, w := range cf.blocklist {
+		if strings.Contains(code, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// blockedSlot reports whether the body of a code contains a word on the
+// blocklist, along with the last slot that has to change for the code to lose
+// the word.  Codes that only differ in later slots contain the word as well,
+// unless it covers a signature or parity character, which change with every
+// code, and then the last slot is returned.  It is -1 if no slot can change
+// the word, as it only covers literals and fixed characters.
+func (cf *CodeFactory) blockedSlot(slots []slot, body []string) (int, bool) {
+	last := len(slots) - 1
+	if len(cf.blocklist) == 0 {
+		return last, false
+	}
+
+	// the lowercase code, and where each slot ends in it
+	code := ""
+	ends := make([]int, len(body))
+	for j, v := range body {
+		code += strings.ToLower(v)
+		ends[j] = len(code)
+	}
+
+	found := false
+	for _, w := range cf.blocklist {
+		i := strings.Index(code, w)
+		if i < 0 {
+			continue
+		}
+		found = true
+		j := -1
+		for k, s := range slots {
+			start := 0
+			if k > 0 {
+				start = ends[k-1]
+			}
+			switch {
+			case ends[k] <= i || start >= i+len(w):
+				// slot k isn't part of the word
+			case s.verb == 's' || s.verb == 'r':
+				j = last
+			case s.random() && k > j:
+				j = k
+			}
+		}
+		if j < last {
+			last = j
+		}
+	}
+	return last, found
+}
+
+// blockedCount returns the number of codes that contain a word on the
+// blocklist, not counting reserved codes.  The codes are only counted if there
+// are at most smallSpace of them, and 0 is returned otherwise.
+func (cf *CodeFactory) blockedCount(slots []slot) int64 {
+	space := randomSpace(slots)
+	if len(cf.blocklist) == 0 || space > smallSpace {
+		return 0
+	}
+	body, err := cf.newBody(slots)
+	if err != nil {
+		return 0
+	}
+	rs, _ := cf.reedSolomon(slots)
+	signed := cf.signed()
+
+	n := int64(0)
+	for idx := int64(0); idx < space; idx++ {
+		unrank(slots, body, idx)
+		if signed {
+			cf.sign(slots, body)
+		}
+		if rs != nil {
+			rs.encode(slots, body)
+		}
+		if cf.blocked(body) && !cf.reserved[cf.prefix+strings.Join(body, "")+cf.suffix] {
+			n++
+		}
+	}
+	return n
+}
+
+// letters returns the lowercase set, or the uppercase set if there are no
+// lowercase letters, keeping the letters for which keep returns true.
+func (cf *CodeFactory) letters(keep func(rune) bool) string {
+	set := cf.lower
+	if set == "" {
+		set = cf.upper
+	}
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) && keep(r) {
+			return r
+		}
+		return -1
+	}, set)
+}
+
+func isVowel(r rune) bool {
+	return isIncludedIn(vowels, r)
+}
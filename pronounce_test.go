@@ -0,0 +1,144 @@
+package codefactory
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPronounceable(t *testing.T) {
+	var testCases = []struct {
+		desc     string
+		cf       *CodeFactory
+		exclude  string
+		pattern  string
+		maxCodes int64
+	}{
+		{
+			desc:     "syllables from the lowercase set",
+			cf:       New(),
+			pattern:  `^[bcdfghjklmnpqrstvwxyz][aeiou][bcdfghjklmnpqrstvwxyz][aeiou]-\d\d$`,
+			maxCodes: 21 * 5 * 21 * 5 * 100,
+		},
+		{
+			desc:     "excluded letters",
+			cf:       New(),
+			exclude:  "qxyu",
+			pattern:  `^[bcdfghjklmnprstvwz][aeio][bcdfghjklmnprstvwz][aeio]-\d\d$`,
+			maxCodes: 18 * 4 * 18 * 4 * 100,
+		},
+		{
+			desc:     "uppercase set when there are no lowercase letters",
+			cf:       NewCrockford(),
+			pattern:  `^[BCDFGHJKMNPQRSTVWXYZ][AE][BCDFGHJKMNPQRSTVWXYZ][AE]-\d\d$`,
+			maxCodes: 20 * 2 * 20 * 2 * 100,
+		},
+	}
+
+	for i, tt := range testCases {
+		Convey(fmt.Sprintf("Case # %d: %s", i, tt.desc), t, func() {
+
+			cf := tt.cf
+			cf.Exclude(tt.exclude)
+			So(cf.SetFormat("kvkv-dd"), ShouldBeNil)
+			So(cf.MaxCodes(), ShouldEqual, tt.maxCodes)
+
+			res, err := cf.Generate(1000)
+			So(err, ShouldBeNil)
+			re := regexp.MustCompile(tt.pattern)
+			for _, code := range res {
+				So(re.MatchString(code), ShouldBeTrue)
+			}
+		})
+	}
+
+	Convey("Codes don't contain blocked words", t, func() {
+
+		cf := New()
+		cf.SetFormat("kvkv")
+		cf.SetSeed(1)
+		cf.SetBlocklist([]string{"BA", "", "ko"})
+		// blocked codes count against the retry budget
+		cf.SetRetryPolicy(FixedPolicy{Percent: 50})
+
+		res, err := cf.Generate(2000)
+		So(err, ShouldBeNil)
+		for _, code := range res {
+			So(strings.Contains(code, "ba"), ShouldBeFalse)
+			So(strings.Contains(code, "ko"), ShouldBeFalse)
+		}
+	})
+
+	Convey("A blocklist that blocks every code", t, func() {
+
+		cf := New()
+		cf.SetFormat("v")
+		cf.SetBlocklist([]string{"a", "e", "i", "o", "u"})
+
+		_, err := cf.Generate(1)
+		So(err, ShouldResemble, &CountError{Requested: 1, Achievable: 0})
+	})
+
+	Convey("Blocked codes are left out of small spaces", t, func() {
+
+		cf := New()
+		cf.SetFormat("dd")
+		cf.SetSeed(1)
+		cf.SetReserved([]string{"00", "01", "13"})
+		cf.SetBlocklist([]string{"13", "42", "99", "50", "77"})
+		So(cf.MaxCodes(), ShouldEqual, 93)
+
+		res, err := cf.Generate(93)
+		So(err, ShouldBeNil)
+		So(len(res), ShouldEqual, 93)
+		for _, code := range []string{"00", "13", "42", "77"} {
+			So(res, ShouldNotContain, code)
+		}
+		_, err = cf.Generate(94)
+		So(err, ShouldResemble, &CountError{Requested: 94, Achievable: 93})
+
+		it, err := cf.Iterator("")
+		So(err, ShouldBeNil)
+		n := 0
+		for it.Next() {
+			So(cf.blocked([]string{it.Code()}), ShouldBeFalse)
+			n++
+		}
+		So(n, ShouldEqual, 93)
+		So(it.Code(), ShouldEqual, "98")
+	})
+
+	Convey("Iterators skip long runs of blocked codes at once", t, func() {
+
+		for _, format := range []string{"lw-baa", "wepbkb", "ll-ss"} {
+			cf := New()
+			cf.SetKey([]byte("secret"))
+			cf.SetFormat(format)
+			cf.SetBlocklist([]string{"a", "e"})
+
+			start := time.Now()
+			it, err := cf.Iterator("")
+			So(err, ShouldBeNil)
+			for i := 0; i < 3; i++ {
+				So(it.Next(), ShouldBeTrue)
+				So(cf.blocked([]string{it.Code()}), ShouldBeFalse)
+			}
+			So(it.Prev(), ShouldBeTrue)
+			So(cf.blocked([]string{it.Code()}), ShouldBeFalse)
+			So(time.Since(start), ShouldBeLessThan, time.Second)
+		}
+
+		// a word in the literals blocks every code
+		cf := New()
+		cf.SetEscape(backslash)
+		cf.SetFormat(`dd-\a\bd`)
+		cf.SetBlocklist([]string{"ab"})
+		it, err := cf.Iterator("")
+		So(err, ShouldBeNil)
+		So(it.Next(), ShouldBeFalse)
+	})
+}
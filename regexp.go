@@ -0,0 +1,219 @@
+package codefactory
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// flavor is a dialect of regular expressions.
+type flavor int
+
+const (
+	re2        flavor = iota // Go's regexp package
+	ecmaScript               // JavaScript, HTML pattern attributes, and JSON Schema
+)
+
+// Regexp returns an anchored regular expression, in the syntax of Go's regexp
+// package, that matches the canonical codes of the CodeFactory, including the
+// prefix and suffix.  It checks which characters may appear at each position,
+// but not the values of signature, time, counter, date, parity, or field
+// characters, nor the reserved codes and blocklist.
+//
+// As the syntax has no lookahead, the codes of a policy can't be matched, and
+// ErrPolicySet is returned while a policy is set.
+func (cf *CodeFactory) Regexp() (string, error) {
+	if cf.policy != nil {
+		return "", ErrPolicySet
+	}
+	return cf.regexp(re2)
+}
+
+// ECMAScriptPattern returns a regular expression in the same way as Regexp,
+// but in the syntax of JavaScript, so that it can be used in the pattern
+// attribute of an HTML input.  It is valid with and without the u and v
+// flags.  The codes of a policy are matched with lookaheads.
+func (cf *CodeFactory) ECMAScriptPattern() (string, error) {
+	return cf.regexp(ecmaScript)
+}
+
+// JSONSchema returns a JSON Schema for a string holding a canonical code of
+// the CodeFactory, with the pattern given by ECMAScriptPattern and the
+// minimum and maximum length of the codes.
+func (cf *CodeFactory) JSONSchema() (string, error) {
+	pattern, err := cf.ECMAScriptPattern()
+	if err != nil {
+		return "", err
+	}
+
+	min, max := cf.codeLengths()
+	schema := struct {
+		Type      string `json:"type"`
+		Pattern   string `json:"pattern"`
+		MinLength int    `json:"minLength"`
+		MaxLength int    `json:"maxLength"`
+	}{"string", pattern, min, max}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(schema); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// regexp returns the regular expression for the codes in the flavor fl.
+func (cf *CodeFactory) regexp(fl flavor) (string, error) {
+	if cf.policy != nil {
+		return cf.policyRegexp()
+	}
+
+	atoms := []string{}
+	for _, s := range cf.slots() {
+		switch {
+		// formatting symbol
+		case s.verb == 0:
+			atoms = append(atoms, quote(s.lit, fl))
+		case len(s.vals) == 0:
+			return "", ErrNoCharacters
+		case s.verb == 'b':
+			words := []string{}
+			for _, w := range s.vals {
+				words = append(words, quote(w, fl))
+			}
+			atoms = append(atoms, "(?:"+strings.Join(words, "|")+")")
+		default:
+			atoms = append(atoms, charClass(strings.Join(s.vals, ""), fl))
+		}
+	}
+
+	// runs of the same atom are counted
+	res := "^" + quote(cf.prefix, fl)
+	for i := 0; i < len(atoms); {
+		n := 1
+		for i+n < len(atoms) && atoms[i+n] == atoms[i] {
+			n++
+		}
+		res += atoms[i]
+		if n > 1 {
+			res += "{" + strconv.Itoa(n) + "}"
+		}
+		i += n
+	}
+	return res + quote(cf.suffix, fl) + "$", nil
+}
+
+// policyRegexp returns the ECMAScript regular expression for the codes of the
+// policy.  Each minimum is a lookahead that counts the characters of its set
+// up to the suffix.
+func (cf *CodeFactory) policyRegexp() (string, error) {
+	if err := cf.checkPolicy(0); err != nil {
+		return "", err
+	}
+	sets, mins := cf.policySets()
+	all := strings.Join(sets, "")
+	suffix := quote(cf.suffix, ecmaScript) + "$"
+
+	res := "^" + quote(cf.prefix, ecmaScript)
+	for i, set := range sets {
+		if mins[i] == 0 {
+			continue
+		}
+		others := strings.Join(sets[:i], "") + strings.Join(sets[i+1:], "")
+		other := "[]"
+		if others != "" {
+			other = charClass(others, ecmaScript)
+		}
+		res += "(?=(?:" + other + "*" + charClass(set, ecmaScript) + "){" + strconv.Itoa(mins[i]) + "}" +
+			charClass(all, ecmaScript) + "*" + suffix + ")"
+	}
+	return res + charClass(all, ecmaScript) + "{" + strconv.Itoa(cf.policy.Length) + "}" + suffix, nil
+}
+
+// codeLengths returns the shortest and longest lengths of the codes, counted
+// in runes.
+func (cf *CodeFactory) codeLengths() (int, int) {
+	n := len([]rune(cf.prefix)) + len([]rune(cf.suffix))
+	if cf.policy != nil {
+		return n + cf.policy.Length, n + cf.policy.Length
+	}
+
+	min, max := n, n
+	for _, s := range cf.slots() {
+		if s.verb != 'b' {
+			min++
+			max++
+			continue
+		}
+		short, long := len([]rune(s.vals[0])), 0
+		for _, w := range s.vals {
+			if l := len([]rune(w)); l < short {
+				short = l
+			} else if l > long {
+				long = l
+			}
+		}
+		min += short
+		max += long
+	}
+	return min, max
+}
+
+// charClass returns a character class matching the characters of set, with
+// runs of three or more consecutive characters given as ranges.  A set of one
+// character is returned as the character itself.
+func charClass(set string, fl flavor) string {
+	runes := []rune(set)
+	if len(runes) == 1 {
+		return quote(set, fl)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+
+	res := "["
+	for i := 0; i < len(runes); {
+		j := i
+		for j+1 < len(runes) && runes[j+1] == runes[j]+1 {
+			j++
+		}
+		if j-i >= 2 {
+			res += quoteClass(runes[i], fl) + "-" + quoteClass(runes[j], fl)
+			i = j + 1
+			continue
+		}
+		res += quoteClass(runes[i], fl)
+		i++
+	}
+	return res + "]"
+}
+
+// quote escapes the characters of s that have a meaning in a regular
+// expression outside a character class.
+func quote(s string, fl flavor) string {
+	if fl == re2 {
+		return regexp.QuoteMeta(s)
+	}
+	res := ""
+	for _, r := range s {
+		if strings.ContainsRune(`^$\.*+?()[]{}|/`, r) {
+			res += `\`
+		}
+		res += string(r)
+	}
+	return res
+}
+
+// quoteClass escapes r if it has a meaning inside a character class.  Go
+// allows any ASCII punctuation to be escaped, while JavaScript with the u or v
+// flag only allows its syntax characters to be.
+func quoteClass(r rune, fl flavor) string {
+	if (fl == re2 && r <= unicode.MaxASCII && !unicode.IsLetter(r) && !unicode.IsDigit(r)) ||
+		(fl == ecmaScript && strings.ContainsRune(`^$\.*+?()[]{}|/-`, r)) {
+		return `\` + string(r)
+	}
+	return string(r)
+}
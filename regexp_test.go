@@ -0,0 +1,159 @@
+package codefactory
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRegexp(t *testing.T) {
+	var testCases = []struct {
+		desc   string
+		cf     *CodeFactory
+		prefix string
+		custom string
+		words  []string
+		format string
+		re2    string
+		ecma   string
+	}{
+		{
+			desc:   "runs of the same set",
+			cf:     New(),
+			prefix: "#",
+			format: "xxxx-dd",
+			re2:    `^#[0-9A-Za-z]{4}-[0-9]{2}$`,
+			ecma:   `^#[0-9A-Za-z]{4}-[0-9]{2}$`,
+		},
+		{
+			desc:   "punctuation in the prefix and custom set",
+			cf:     New(),
+			prefix: "a.b(",
+			custom: "+-.!/",
+			format: "cc/c",
+			re2:    `^a\.b\([\!\+\--\/]{2}/[\!\+\--\/]$`,
+			ecma:   `^a\.b\([!\+\--\/]{2}\/[!\+\--\/]$`,
+		},
+		{
+			desc:   "excluded characters and escaped literals",
+			cf:     NewHex(),
+			format: `\4xx-dx`,
+			re2:    `^4[0-9a-f]{2}-[0-9][0-9a-f]$`,
+			ecma:   `^4[0-9a-f]{2}-[0-9][0-9a-f]$`,
+		},
+		{
+			desc:   "Crockford with signature and parity characters",
+			cf:     NewCrockford(),
+			format: "xxss-rr",
+			re2:    `^[0-9A-HJKMNP-TV-Z]{4}-[0-9A-HJKMNP-TV-Z]{2}$`,
+			ecma:   `^[0-9A-HJKMNP-TV-Z]{4}-[0-9A-HJKMNP-TV-Z]{2}$`,
+		},
+		{
+			desc:   "words",
+			cf:     New(),
+			words:  []string{"red", "blue"},
+			format: "b-bd",
+			re2:    `^(?:red|blue)-(?:red|blue)[0-9]$`,
+			ecma:   `^(?:red|blue)-(?:red|blue)[0-9]$`,
+		},
+	}
+
+	for i, tt := range testCases {
+		Convey(fmt.Sprintf("Case # %d: %s", i, tt.desc), t, func() {
+
+			cf := tt.cf
+			cf.SetEscape(backslash)
+			cf.SetPrefix(tt.prefix)
+			cf.SetCustom(tt.custom)
+			cf.SetWords(tt.words)
+			cf.SetKey([]byte("key"))
+			So(cf.SetFormat(tt.format), ShouldBeNil)
+
+			re, err := cf.Regexp()
+			So(err, ShouldBeNil)
+			So(re, ShouldEqual, tt.re2)
+			ecma, err := cf.ECMAScriptPattern()
+			So(err, ShouldBeNil)
+			So(ecma, ShouldEqual, tt.ecma)
+
+			cf.SetSeed(1)
+			res, err := cf.Generate(20)
+			So(err, ShouldBeNil)
+			for _, code := range res {
+				So(regexp.MustCompile(re).MatchString(code), ShouldBeTrue)
+				So(regexp.MustCompile(ecma).MatchString(code), ShouldBeTrue)
+			}
+		})
+	}
+
+	Convey("The expression matches exactly the codes that validate", t, func() {
+
+		cf := New()
+		cf.Exclude("ilo01")
+		cf.SetPrefix("ab-")
+		cf.SetFormat("xx-dw")
+		re := regexp.MustCompile(func() string { s, _ := cf.Regexp(); return s }())
+
+		rng := rand.New(rand.NewSource(1))
+		chars := []rune("ab-019iloAZ#")
+		for n := 0; n < 5000; n++ {
+			code := "ab-"
+			for j := rng.Intn(7); j > 0; j-- {
+				code += string(chars[rng.Intn(len(chars))])
+			}
+			So(re.MatchString(code), ShouldEqual, cf.Validate(code) == nil)
+		}
+	})
+
+	Convey("Policies are matched with lookaheads", t, func() {
+
+		cf := New()
+		cf.Exclude(defaultLowercase)
+		cf.SetCustom("!#")
+		cf.SetSuffix("*")
+		cf.SetPolicy(Policy{Length: 8, Digits: 2, Custom: 1})
+
+		_, err := cf.Regexp()
+		So(err, ShouldEqual, ErrPolicySet)
+
+		ecma, err := cf.ECMAScriptPattern()
+		So(err, ShouldBeNil)
+		So(ecma, ShouldEqual, `^(?=(?:[!#A-Z]*[0-9]){2}[!#0-9A-Z]*\*$)`+
+			`(?=(?:[0-9A-Z]*[!#]){1}[!#0-9A-Z]*\*$)[!#0-9A-Z]{8}\*$`)
+
+		cf.SetPolicy(Policy{Length: 8, Custom: 1})
+		cf.SetCustom("")
+		_, err = cf.ECMAScriptPattern()
+		So(err, ShouldEqual, ErrNoCharacters)
+	})
+}
+
+func TestJSONSchema(t *testing.T) {
+
+	Convey("The schema holds the pattern and the lengths of the codes", t, func() {
+
+		cf := New()
+		cf.SetPrefix("#")
+		cf.SetFormat("b-dd")
+		cf.SetWords([]string{"red", "blue", "green"})
+
+		s, err := cf.JSONSchema()
+		So(err, ShouldBeNil)
+		So(s, ShouldEqual, `{"type":"string","pattern":"^#(?:red|blue|green)-[0-9]{2}$","minLength":7,"maxLength":9}`)
+
+		var schema map[string]interface{}
+		So(json.Unmarshal([]byte(s), &schema), ShouldBeNil)
+		So(schema["minLength"], ShouldEqual, 7)
+
+		cf.SetPolicy(Policy{Length: 12, Digits: 1})
+		s, err = cf.JSONSchema()
+		So(err, ShouldBeNil)
+		So(json.Unmarshal([]byte(s), &schema), ShouldBeNil)
+		So(schema["minLength"], ShouldEqual, 13)
+		So(schema["maxLength"], ShouldEqual, 13)
+	})
+}
@@ -0,0 +1,91 @@
+package codefactory
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Report describes how a batch of codes was generated, for use in audit logs.
+type Report struct {
+	Duplicates  int           // duplicate codes generated and discarded
+	RetryBudget int           // duplicates allowed before giving up
+	Elapsed     time.Duration // time taken to generate the codes
+	Utilization float64       // codes requested as a fraction of MaxCodes
+	Entropy     float64       // bits of randomness in each code, see Entropy
+	Fingerprint string        // fingerprint of the settings, see Fingerprint
+	Seed        int64         // seed of the random numbers, if Seeded
+	Seeded      bool          // whether a seed was set with SetSeed
+}
+
+// GenerateWithReport generates `num` codes in the same way as Generate, and
+// also returns a report on how they were generated.  The report is returned
+// even if generating the codes fails, to show how close the batch came to
+// ErrMaxRetriesExceeded.
+func (cf *CodeFactory) GenerateWithReport(num int) ([]string, Report, error) {
+	return cf.generate(context.Background(), num, nil)
+}
+
+// SetSeed seeds the random numbers used to generate codes, so that the same
+// settings and seed always generate the same codes.  Without a seed, the
+// shared source of math/rand is used.
+func (cf *CodeFactory) SetSeed(seed int64) {
+	cf.rnd = rand.New(rand.NewSource(seed))
+	cf.seed = seed
+}
+
+// Fingerprint returns a hex encoded hash of the settings that affect which
+// codes are generated, so that batches generated with the same settings can
+// be recognised.  The key used for signing is not part of the fingerprint, but
+// whether one is set is.  For formats with counter characters, the next value
+// of the counter is part of it, so batches that follow each other have
+// different fingerprints.
+func (cf *CodeFactory) Fingerprint() string {
+	h := sha256.New()
+	for _, s := range []string{cf.num, cf.lower, cf.upper, cf.custom, cf.format, cf.prefix, cf.suffix} {
+		fmt.Fprintf(h, "%d:%s", len(s), s)
+	}
+	for _, f := range cf.fields {
+		fmt.Fprintf(h, "%d:%s%d:%d", len(f.name), f.name, f.max, f.value)
+	}
+	if cf.timed() {
+		fmt.Fprintf(h, "%d", cf.timePrecision())
+	}
+	if cf.escape != 0 {
+		fmt.Fprintf(h, "%c", cf.escape)
+	}
+	if cf.counted() {
+		fmt.Fprintf(h, "%d:%d", cf.counter, cf.counterStep())
+	}
+	if cf.minDist > 1 {
+		fmt.Fprintf(h, "%d:%d", cf.minDist, cf.metric)
+		for _, c := range cf.issued {
+			fmt.Fprintf(h, "%d:%s", len(c), c)
+		}
+	}
+	for _, w := range cf.blocklist {
+		fmt.Fprintf(h, "%d:%s", len(w), w)
+	}
+	reserved := []string{}
+	for c := range cf.reserved {
+		reserved = append(reserved, c)
+	}
+	sort.Strings(reserved)
+	for _, c := range reserved {
+		fmt.Fprintf(h, "%d:%s", len(c), c)
+	}
+	if p := cf.policy; p != nil {
+		fmt.Fprintf(h, "%d:%d:%d:%d:%d", p.Length, p.Digits, p.Upper, p.Lower, p.Custom)
+	}
+	if cf.hasVerb('b') {
+		for _, w := range cf.wordList() {
+			fmt.Fprintf(h, "%d:%s", len(w), w)
+		}
+	}
+	fmt.Fprintf(h, "%t", cf.key != nil)
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
@@ -0,0 +1,130 @@
+package codefactory
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGenerateWithReport(t *testing.T) {
+
+	Convey("Report on a batch of codes", t, func() {
+
+		cf := New()
+		cf.SetFormat("ddd")
+
+		res, rep, err := cf.GenerateWithReport(100)
+
+		So(err, ShouldBeNil)
+		So(len(res), ShouldEqual, 100)
+		So(rep.RetryBudget, ShouldEqual, defaultRetryPolicy.MaxRetries(100, 1000))
+		So(rep.Duplicates, ShouldBeLessThanOrEqualTo, rep.RetryBudget)
+		So(rep.Utilization, ShouldAlmostEqual, 0.1)
+		So(rep.Elapsed, ShouldBeGreaterThan, 0)
+		So(rep.Fingerprint, ShouldEqual, cf.Fingerprint())
+		So(rep.Seeded, ShouldBeFalse)
+	})
+
+	Convey("The retry budget shrinks as the format gets longer", t, func() {
+
+		budgets := []int{}
+		for _, format := range []string{"xxxxx", "xxxxxx", "xxxxxxxxxxxx"} {
+			cf := New()
+			cf.SetFormat(format)
+			_, rep, err := cf.GenerateWithReport(100000)
+			So(err, ShouldBeNil)
+			budgets = append(budgets, rep.RetryBudget)
+		}
+		So(budgets[0], ShouldBeGreaterThan, budgets[1])
+		So(budgets[1], ShouldBeGreaterThan, budgets[2])
+		So(budgets[2], ShouldEqual, defaultRetryPolicy.MaxRetries(100000, math.MaxInt64))
+	})
+
+	Convey("Report on a failed batch", t, func() {
+
+		cf := New()
+		cf.SetFormat("$ dd")
+		cf.SetRetryPolicy(FixedPolicy{Base: 1})
+		cf.SetSeed(1)
+
+		res, rep, err := cf.GenerateWithReport(40)
+
+		So(err, ShouldEqual, ErrMaxRetriesExceeded)
+		So(res, ShouldResemble, []string{})
+		So(rep.Duplicates, ShouldEqual, rep.RetryBudget+1)
+	})
+
+	Convey("Report the seed", t, func() {
+
+		cf := New()
+		cf.SetSeed(42)
+
+		_, rep, err := cf.GenerateWithReport(10)
+
+		So(err, ShouldBeNil)
+		So(rep.Seeded, ShouldBeTrue)
+		So(rep.Seed, ShouldEqual, 42)
+	})
+}
+
+func TestSetSeed(t *testing.T) {
+
+	Convey("The same seed generates the same codes", t, func() {
+
+		a, b := New(), New()
+		a.SetSeed(7)
+		b.SetSeed(7)
+
+		resA, err := a.Generate(1000)
+		So(err, ShouldBeNil)
+		resB, err := b.Generate(1000)
+		So(err, ShouldBeNil)
+
+		sort.Strings(resA)
+		sort.Strings(resB)
+		So(resA, ShouldResemble, resB)
+	})
+}
+
+func TestFingerprint(t *testing.T) {
+
+	Convey("The fingerprint follows the settings", t, func() {
+
+		a, b := New(), New()
+		So(a.Fingerprint(), ShouldEqual, b.Fingerprint())
+		So(len(a.Fingerprint()), ShouldEqual, 32)
+
+		b.SetFormat("#xxxxx")
+		So(a.Fingerprint(), ShouldNotEqual, b.Fingerprint())
+
+		// moving a character between settings changes the fingerprint
+		a.SetPrefix("ab")
+		b = New()
+		b.SetPrefix("a")
+		b.SetSuffix("b")
+		So(a.Fingerprint(), ShouldNotEqual, b.Fingerprint())
+
+		b = New()
+		b.SetPrefix("ab")
+		b.SetKey([]byte("secret"))
+		So(a.Fingerprint(), ShouldNotEqual, b.Fingerprint())
+	})
+
+	Convey("The fingerprint follows the counter", t, func() {
+
+		a, b := New(), New()
+		a.SetFormat("nnnn-xx")
+		b.SetFormat("nnnn-xx")
+		a.SetCounter(0, 1)
+		b.SetCounter(5000, 1)
+		So(a.Fingerprint(), ShouldNotEqual, b.Fingerprint())
+
+		fp := a.Fingerprint()
+		_, rep, err := a.GenerateWithReport(10)
+		So(err, ShouldBeNil)
+		So(rep.Fingerprint, ShouldEqual, fp)
+		So(a.Fingerprint(), ShouldNotEqual, fp)
+	})
+}
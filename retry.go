@@ -0,0 +1,98 @@
+package codefactory
+
+import "math"
+
+// RetryPolicy decides how many duplicate codes Generate may discard before it
+// gives up with ErrMaxRetriesExceeded.
+type RetryPolicy interface {
+	// MaxRetries returns the number of duplicates allowed when generating
+	// `num` codes out of `space` possible codes.
+	MaxRetries(num int, space int64) int
+}
+
+// FixedPolicy allows a fixed percentage of the number of codes requested, plus
+// a base number of duplicates, regardless of the size of the space.
+type FixedPolicy struct {
+	Percent int
+	Base    int
+}
+
+// MaxRetries implements RetryPolicy.
+func (p FixedPolicy) MaxRetries(num int, space int64) int {
+	return num*p.Percent/100 + p.Base
+}
+
+// BirthdayPolicy allows the number of duplicates expected when drawing `num`
+// unique codes at random out of `space`, plus a margin of Sigmas standard
+// deviations and a base number of duplicates.  This is the default policy.
+type BirthdayPolicy struct {
+	Sigmas float64
+	Base   int
+}
+
+// MaxRetries implements RetryPolicy.
+func (p BirthdayPolicy) MaxRetries(num int, space int64) int {
+	mean, variance := duplicateStats(num, space)
+	return int(math.Ceil(mean+p.Sigmas*math.Sqrt(variance))) + p.Base
+}
+
+// ExpectedDuplicates returns the number of duplicates expected when drawing
+// `num` unique codes at random out of `space` possible codes.
+func ExpectedDuplicates(num int, space int64) float64 {
+	mean, _ := duplicateStats(num, space)
+	return mean
+}
+
+// SetRetryPolicy sets the policy deciding how many duplicates Generate may
+// discard.  A nil policy restores the default BirthdayPolicy.
+func (cf *CodeFactory) SetRetryPolicy(p RetryPolicy) {
+	cf.retry = p
+}
+
+// Feasible checks, without generating any codes, whether `num` codes can be
+// generated with the current settings.  It returns the error that Generate
+// would return for settings that can't work, and ErrInfeasible if the retry
+// policy allows fewer duplicates than are expected, so that Generate would
+// fail more often than not.
+//
+// Dense requests, for at least half of the possible codes, are always
+// feasible, as Generate draws them without replacement.  So are codes with a
+// counter, which can't be duplicates.
+func (cf *CodeFactory) Feasible(num int) error {
+	if err := cf.check(num); err != nil {
+		return err
+	}
+	if cf.counted() || cf.dense(num, randomSpace(cf.slots())) {
+		return nil
+	}
+	space := cf.drawSpace(cf.slots())
+	if cf.policy != nil {
+		space = cf.policySpace()
+	}
+	if float64(cf.maxRetries(num, space)) < ExpectedDuplicates(num, space) {
+		return ErrInfeasible
+	}
+	return nil
+}
+
+// maxRetries returns the number of duplicates allowed by the retry policy.
+func (cf *CodeFactory) maxRetries(num int, space int64) int {
+	if cf.retry == nil {
+		return defaultRetryPolicy.MaxRetries(num, space)
+	}
+	return cf.retry.MaxRetries(num, space)
+}
+
+// duplicateStats returns the mean and variance of the number of duplicates
+// drawn before `num` unique codes are drawn out of `space`.  With i codes
+// already drawn, the number of duplicates before the next new code follows a
+// geometric distribution with a probability of success of (space-i)/space.
+func duplicateStats(num int, space int64) (mean, variance float64) {
+	n := float64(space)
+	for i := 0; i < num && int64(i) < space; i++ {
+		f := float64(i)
+		mean += f / (n - f)
+		variance += f * n / ((n - f) * (n - f))
+	}
+	return mean, variance
+}
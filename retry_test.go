@@ -0,0 +1,81 @@
+package codefactory
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestExpectedDuplicates(t *testing.T) {
+
+	Convey("Expected duplicates follow the birthday bound", t, func() {
+
+		So(ExpectedDuplicates(0, 10), ShouldEqual, 0)
+		So(ExpectedDuplicates(1, 10), ShouldEqual, 0)
+		So(ExpectedDuplicates(2, 2), ShouldAlmostEqual, 1)
+		So(ExpectedDuplicates(2, 4), ShouldAlmostEqual, 1.0/3)
+		So(ExpectedDuplicates(3, 4), ShouldAlmostEqual, 1.0/3+2.0/2)
+
+		// about num^2/2N for sparse spaces
+		So(ExpectedDuplicates(1000, 1e7), ShouldAlmostEqual, 0.05, 0.001)
+	})
+}
+
+func TestRetryPolicy(t *testing.T) {
+
+	Convey("The fixed policy ignores the space", t, func() {
+
+		p := FixedPolicy{Percent: 10, Base: 4}
+		So(p.MaxRetries(90, 100), ShouldEqual, 13)
+		So(p.MaxRetries(90, 1e7), ShouldEqual, 13)
+	})
+
+	Convey("The birthday policy follows the space", t, func() {
+
+		p := BirthdayPolicy{Sigmas: 4, Base: 4}
+		So(p.MaxRetries(1000, 1e7), ShouldEqual, 5)
+		So(p.MaxRetries(90, 100), ShouldBeGreaterThan, ExpectedDuplicates(90, 100))
+	})
+
+	Convey("Dense batches succeed with the default policy", t, func() {
+
+		cf := New()
+		cf.SetFormat("$ dd")
+
+		res, err := cf.Generate(90)
+		So(err, ShouldBeNil)
+		So(len(res), ShouldEqual, 90)
+	})
+
+	Convey("A nil policy restores the default", t, func() {
+
+		cf := New()
+		cf.SetRetryPolicy(FixedPolicy{})
+		So(cf.maxRetries(1000, 1e7), ShouldEqual, 0)
+		cf.SetRetryPolicy(nil)
+		So(cf.maxRetries(1000, 1e7), ShouldEqual, 5)
+	})
+}
+
+func TestFeasible(t *testing.T) {
+
+	Convey("Check feasibility before generating", t, func() {
+
+		cf := New()
+		cf.SetFormat("$ dd")
+		So(cf.Feasible(90), ShouldBeNil)
+
+		cf.SetRetryPolicy(FixedPolicy{Percent: 10, Base: 4})
+		So(cf.Feasible(20), ShouldBeNil)
+		So(cf.Feasible(40), ShouldEqual, ErrInfeasible)
+
+		// drawn without replacement
+		So(cf.Feasible(90), ShouldBeNil)
+
+		So(errors.Is(cf.Feasible(101), ErrTooManyCodes), ShouldBeTrue)
+
+		cf.num = ""
+		So(cf.Feasible(1), ShouldEqual, ErrNoCharacters)
+	})
+}
@@ -0,0 +1,96 @@
+package codefactory
+
+import (
+	"math/rand"
+)
+
+// randSource is the part of *rand.Rand used to generate codes.
+type randSource interface {
+	Intn(n int) int
+	Int63n(n int64) int64
+}
+
+// globalRand uses the shared source of math/rand.
+type globalRand struct{}
+
+func (globalRand) Intn(n int) int       { return rand.Intn(n) }
+func (globalRand) Int63n(n int64) int64 { return rand.Int63n(n) }
+
+// rng returns the source of random numbers for generating codes, which is the
+// seeded source if SetSeed has been called.
+func (cf *CodeFactory) rng() randSource {
+	if cf.rnd != nil {
+		return cf.rnd
+	}
+	return globalRand{}
+}
+
+// randomSpace returns the number of ways the random slots can be filled,
+// limited to math.MaxInt64.
+func randomSpace(slots []slot) int64 {
+	space := int64(1)
+	for _, s := range slots {
+		if s.random() {
+			space = mulCapped(space, int64(len(s.vals)))
+		}
+	}
+	return space
+}
+
+// dense reports whether `num` codes are drawn without replacement from the
+// `space` ways of filling the random slots, as most random codes would be
+// duplicates.
+func (cf *CodeFactory) dense(num int, space int64) bool {
+	return cf.policy == nil && !cf.counted() && cf.minDist <= 1 &&
+		int64(num)*denseFactor >= space
+}
+
+// unrank fills the random slots of body with the code at position idx in the
+// space of codes, taking the last random slot as the least significant.
+func unrank(slots []slot, body []string, idx int64) {
+	for j := len(slots) - 1; j >= 0; j-- {
+		if !slots[j].random() {
+			continue
+		}
+		n := int64(len(slots[j].vals))
+		body[j] = slots[j].vals[idx%n]
+		idx /= n
+	}
+}
+
+// sampler draws distinct indexes at random from [0, space), one at a time,
+// with a partial Fisher-Yates shuffle of the space.  Only the positions that
+// have been swapped are stored, so it needs memory for at most the indexes
+// drawn, and nothing is drawn before it is needed.
+type sampler struct {
+	rng   randSource
+	space int64
+	drawn int64
+	swaps map[int64]int64
+}
+
+func newSampler(rng randSource, space int64) *sampler {
+	return &sampler{rng: rng, space: space, swaps: map[int64]int64{}}
+}
+
+// next returns the next index, and false once every index has been drawn.
+func (s *sampler) next() (int64, bool) {
+	if s.drawn == s.space {
+		return 0, false
+	}
+	j := s.drawn + s.rng.Int63n(s.space-s.drawn)
+	v, ok := s.swaps[j]
+	if !ok {
+		v = j
+	}
+
+	// position j now holds the index at the front of the unshuffled part
+	front, ok := s.swaps[s.drawn]
+	if !ok {
+		front = s.drawn
+	}
+	s.swaps[j] = front
+	delete(s.swaps, s.drawn)
+	s.drawn++
+	return v, true
+}
@@ -0,0 +1,111 @@
+package codefactory
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSampler(t *testing.T) {
+	var testCases = []struct {
+		desc  string
+		num   int
+		space int64
+	}{
+		{
+			desc:  "all of a small space",
+			num:   100,
+			space: 100,
+		},
+		{
+			desc:  "part of a small space",
+			num:   1000,
+			space: smallSpace,
+		},
+		{
+			desc:  "most of a larger space",
+			num:   50000,
+			space: smallSpace + 1,
+		},
+		{
+			desc:  "all of a larger space",
+			num:   100000,
+			space: 100000,
+		},
+	}
+
+	for i, tt := range testCases {
+		Convey(fmt.Sprintf("Case # %d: %s", i, tt.desc), t, func() {
+
+			smp := newSampler(rand.New(rand.NewSource(1)), tt.space)
+			seen := map[int64]bool{}
+			for n := 0; n < tt.num; n++ {
+				v, ok := smp.next()
+				So(ok, ShouldBeTrue)
+				So(v >= 0 && v < tt.space, ShouldBeTrue)
+				seen[v] = true
+			}
+			So(len(seen), ShouldEqual, tt.num)
+
+			// the space runs out once every index has been drawn
+			if int64(tt.num) == tt.space {
+				_, ok := smp.next()
+				So(ok, ShouldBeFalse)
+			}
+		})
+	}
+}
+
+func TestUnrank(t *testing.T) {
+
+	Convey("Unrank counts through the random slots", t, func() {
+
+		cf := New()
+		cf.SetFormat("d-ll")
+		slots := cf.slots()
+		body := []string{"", "-", "", ""}
+
+		unrank(slots, body, 0)
+		So(body, ShouldResemble, []string{"0", "-", "a", "a"})
+
+		unrank(slots, body, 27)
+		So(body, ShouldResemble, []string{"0", "-", "b", "b"})
+
+		unrank(slots, body, 10*26*26-1)
+		So(body, ShouldResemble, []string{"9", "-", "z", "z"})
+	})
+}
+
+func TestGenerateDense(t *testing.T) {
+
+	Convey("Generate every possible 4-digit PIN", t, func() {
+
+		cf := New()
+		cf.SetFormat("dddd")
+
+		res, rep, err := cf.GenerateWithReport(10000)
+
+		So(err, ShouldBeNil)
+		So(rep.Duplicates, ShouldEqual, 0)
+		sort.Strings(res)
+		for i, code := range res {
+			So(code, ShouldEqual, fmt.Sprintf("%04d", i))
+		}
+	})
+
+	Convey("Generate most of a larger space", t, func() {
+
+		cf := New()
+		cf.SetKey([]byte("secret"))
+		cf.SetFormat("ddddd-s")
+
+		res, err := cf.Generate(90000)
+
+		So(err, ShouldBeNil)
+		So(len(res), ShouldEqual, 90000)
+		So(cf.Validate(res[0]), ShouldBeNil)
+	})
+}
@@ -0,0 +1,179 @@
+package codefactory
+
+import (
+	"context"
+	"math"
+	"math/big"
+)
+
+// span is a range of indexes into the space of codes, starting at lo.
+type span struct {
+	lo   *big.Int
+	size *big.Int
+}
+
+// int64 returns the size of the span, limited to math.MaxInt64.
+func (sp *span) int64() int64 {
+	if !sp.size.IsInt64() {
+		return math.MaxInt64
+	}
+	return sp.size.Int64()
+}
+
+// maxCodes returns the size of the span, limited to maxNumCodes.
+func (sp *span) maxCodes() int64 {
+	if n := sp.int64(); n < maxNumCodes {
+		return n
+	}
+	return maxNumCodes
+}
+
+// Shard is one of a number of disjoint parts of the space of codes of a
+// CodeFactory.  Codes generated from different shards of the same split never
+// collide, so separate processes can each generate codes from their own shard
+// without any coordination.
+//
+// The space is split into contiguous ranges, ordered in the same way as an
+// Iterator.  When the number of shards divides the size of the set of the
+// leading code character, each shard owns whole leading characters.
+type Shard struct {
+	cf *CodeFactory
+	sp *span
+}
+
+// Shard returns shard `i` of `n` shards of the space of codes.  The shard
+// keeps a copy of the settings of the CodeFactory at the time it is created,
+// so that later changes can't make shards overlap, and shards can generate
+// codes on separate goroutines.  If the CodeFactory is seeded, shard `i` is
+// seeded with its seed plus `i`.  ErrPolicySet is returned while a policy is
+// set.
+func (cf *CodeFactory) Shard(i, n int) (*Shard, error) {
+	if cf.policy != nil {
+		return nil, ErrPolicySet
+	}
+	if err := cf.check(0); err != nil {
+		return nil, err
+	}
+	space := spaceBig(cf.slots())
+	if n < 1 || i < 0 || i >= n || space.Cmp(big.NewInt(int64(n))) < 0 {
+		return nil, ErrInvalidShard
+	}
+
+	// shard i covers [space*i/n, space*(i+1)/n)
+	lo := new(big.Int).Mul(space, big.NewInt(int64(i)))
+	lo.Div(lo, big.NewInt(int64(n)))
+	hi := new(big.Int).Mul(space, big.NewInt(int64(i+1)))
+	hi.Div(hi, big.NewInt(int64(n)))
+
+	c := cf.clone()
+	if cf.rnd != nil {
+		c.SetSeed(cf.seed + int64(i))
+	}
+	return &Shard{
+		cf: c,
+		sp: &span{lo: lo, size: hi.Sub(hi, lo)},
+	}, nil
+}
+
+// clone returns a copy of cf that shares no maps or slices with it.  The
+// seeded source of random numbers is shared, so the caller should seed the
+// copy.
+func (cf *CodeFactory) clone() *CodeFactory {
+	c := *cf
+	c.key = append([]byte(nil), cf.key...)
+	c.salt = append([]byte(nil), cf.salt...)
+	c.fields = append([]field{}, cf.fields...)
+	c.blocklist = append([]string(nil), cf.blocklist...)
+	c.words = append([]string(nil), cf.words...)
+	c.issued = append([]string(nil), cf.issued...)
+	if cf.reserved != nil {
+		c.reserved = map[string]bool{}
+		for k := range cf.reserved {
+			c.reserved[k] = true
+		}
+	}
+	if cf.aliases != nil {
+		c.aliases = map[rune]rune{}
+		for k, v := range cf.aliases {
+			c.aliases[k] = v
+		}
+	}
+	if cf.policy != nil {
+		p := *cf.policy
+		c.policy = &p
+	}
+	return &c
+}
+
+// Generate generates `num` codes from the shard in the same way as Generate
+// does for the whole CodeFactory.
+func (s *Shard) Generate(num int) ([]string, error) {
+	res, _, err := s.cf.generate(context.Background(), num, s.sp)
+	return res, err
+}
+
+// MaxCodes returns the maximum number of codes that can be generated from the
+// shard, limited in the same way as MaxCodes for the whole CodeFactory.
+func (s *Shard) MaxCodes() int64 {
+	return s.sp.maxCodes()
+}
+
+// Contains reports whether the canonical code belongs to the shard.
+func (s *Shard) Contains(code string) bool {
+	slots, body, err := s.cf.parse(code)
+	if err != nil {
+		return false
+	}
+	idx := rankBig(slots, body)
+	hi := new(big.Int).Add(s.sp.lo, s.sp.size)
+	return idx.Cmp(s.sp.lo) >= 0 && idx.Cmp(hi) < 0
+}
+
+// spaceBig returns the exact number of ways the random slots can be filled.
+func spaceBig(slots []slot) *big.Int {
+	space := big.NewInt(1)
+	for _, s := range slots {
+		if s.random() {
+			space.Mul(space, big.NewInt(int64(len(s.vals))))
+		}
+	}
+	return space
+}
+
+// unrankBig fills the random slots of body in the same way as unrank for an
+// index of any size.
+func unrankBig(slots []slot, body []string, idx *big.Int) {
+	n, m := new(big.Int).Set(idx), new(big.Int)
+	for j := len(slots) - 1; j >= 0; j-- {
+		if !slots[j].random() {
+			continue
+		}
+		n.DivMod(n, big.NewInt(int64(len(slots[j].vals))), m)
+		body[j] = slots[j].vals[m.Int64()]
+	}
+}
+
+// rankBig returns the index of the random slots of body, as the inverse of
+// unrankBig.
+func rankBig(slots []slot, body []string) *big.Int {
+	idx := new(big.Int)
+	for j, s := range slots {
+		if !s.random() {
+			continue
+		}
+		idx.Mul(idx, big.NewInt(int64(len(s.vals))))
+		idx.Add(idx, big.NewInt(int64(indexOf(s.vals, body[j]))))
+	}
+	return idx
+}
+
+// randomBig returns a random number in [0, n).  It draws 64 more bits than n
+// needs, so the bias of the final modulo is negligible.
+func randomBig(rng randSource, n *big.Int) *big.Int {
+	res := new(big.Int)
+	for bits := 0; bits < n.BitLen()+64; bits += 62 {
+		res.Lsh(res, 62)
+		res.Or(res, big.NewInt(rng.Int63n(1<<62)))
+	}
+	return res.Mod(res, n)
+}
@@ -0,0 +1,145 @@
+package codefactory
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestShard(t *testing.T) {
+	var testCases = []struct {
+		desc    string
+		format  string
+		n       int
+		num     int
+		wantMax []int64
+	}{
+		{
+			desc:    "shards own whole leading characters",
+			format:  "ddd",
+			n:       5,
+			num:     150,
+			wantMax: []int64{200, 200, 200, 200, 200},
+		},
+		{
+			desc:    "uneven shards",
+			format:  "dd",
+			n:       3,
+			num:     33,
+			wantMax: []int64{33, 33, 34},
+		},
+		{
+			desc:    "shards of a large space",
+			format:  "xxxxxxxxxxxxxxxx",
+			n:       4,
+			num:     1000,
+			wantMax: []int64{maxNumCodes, maxNumCodes, maxNumCodes, maxNumCodes},
+		},
+	}
+
+	for i, tt := range testCases {
+		Convey(fmt.Sprintf("Case # %d: %s", i, tt.desc), t, func() {
+
+			cf := New()
+			cf.SetFormat(tt.format)
+			cf.SetSeed(1)
+
+			seen := map[string]int{}
+			for k := 0; k < tt.n; k++ {
+				s, err := cf.Shard(k, tt.n)
+				So(err, ShouldBeNil)
+				So(s.MaxCodes(), ShouldEqual, tt.wantMax[k])
+
+				res, err := s.Generate(tt.num)
+				So(err, ShouldBeNil)
+				So(len(res), ShouldEqual, tt.num)
+				for _, code := range res {
+					So(s.Contains(code), ShouldBeTrue)
+					_, ok := seen[code]
+					So(ok, ShouldBeFalse)
+					seen[code] = k
+				}
+			}
+		})
+	}
+
+	Convey("Shards keep their own copy of the settings", t, func() {
+
+		cf := New()
+		cf.SetFormat("dd")
+		s, err := cf.Shard(1, 10)
+		So(err, ShouldBeNil)
+
+		cf.SetFormat("ll")
+		res, err := s.Generate(10)
+		So(err, ShouldBeNil)
+		for _, code := range res {
+			So(code[0], ShouldEqual, '1')
+		}
+		So(s.Contains("15"), ShouldBeTrue)
+		So(s.Contains("25"), ShouldBeFalse)
+		So(s.Contains("ab"), ShouldBeFalse)
+	})
+
+	Convey("Seeded shards generate concurrently and reproducibly", t, func() {
+
+		cf := New()
+		cf.SetFormat("xxxxxx")
+		cf.SetSeed(1)
+		cf.SetReserved([]string{"AAAAAA"})
+		cf.SetWords([]string{"red", "blue"})
+
+		generate := func() [][]string {
+			res := make([][]string, 4)
+			var wg sync.WaitGroup
+			for k := range res {
+				s, err := cf.Shard(k, len(res))
+				So(err, ShouldBeNil)
+				wg.Add(1)
+				go func(k int) {
+					defer wg.Done()
+					res[k], _ = s.Generate(2000)
+					sort.Strings(res[k])
+				}(k)
+			}
+			wg.Wait()
+			return res
+		}
+
+		first, second := generate(), generate()
+		So(second, ShouldResemble, first)
+		seen := map[string]bool{}
+		for _, codes := range first {
+			So(len(codes), ShouldEqual, 2000)
+			for _, code := range codes {
+				seen[code] = true
+			}
+		}
+		So(len(seen), ShouldEqual, 8000)
+	})
+
+	Convey("Shards can't generate more codes than they hold", t, func() {
+
+		cf := New()
+		cf.SetFormat("dd")
+		s, err := cf.Shard(0, 4)
+		So(err, ShouldBeNil)
+
+		_, err = s.Generate(26)
+		So(err, ShouldResemble, &CountError{Requested: 26, Achievable: 25})
+	})
+
+	Convey("Invalid shards are rejected", t, func() {
+
+		cf := New()
+		cf.SetFormat("d")
+		for _, in := range [][2]int{{0, 0}, {-1, 2}, {2, 2}, {0, 11}} {
+			s, err := cf.Shard(in[0], in[1])
+			So(err, ShouldEqual, ErrInvalidShard)
+			So(s, ShouldBeNil)
+		}
+	})
+}
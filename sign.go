@@ -0,0 +1,86 @@
+package codefactory
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"math/big"
+)
+
+// SetKey sets the secret key used to sign codes.  When the format contains
+// signature characters ('s'), they are filled with a truncated HMAC-SHA256 of
+// the rest of the code, rendered in the same set as 'x'.  This allows a code
+// to be checked with Validate using only the key, without a list of the
+// issued codes.
+//
+// An empty key clears the key.
+func (cf *CodeFactory) SetKey(key []byte) {
+	if len(key) == 0 {
+		cf.key = nil
+		return
+	}
+	cf.key = append([]byte{}, key...)
+}
+
+// ForgeryProbability returns the probability that a code made up without the
+// key passes Validate.  This is set by the number of signature characters in
+// the format and the size of their set, and is 1 if the format has no
+// signature characters.
+func (cf *CodeFactory) ForgeryProbability() float64 {
+	p := 1.0
+	for _, s := range cf.slots() {
+		if s.verb == 's' && len(s.vals) > 0 {
+			p /= float64(len(s.vals))
+		}
+	}
+	return p
+}
+
+// signed reports whether the format contains signature characters.
+func (cf *CodeFactory) signed() bool {
+	return cf.hasVerb('s')
+}
+
+// sign fills the signature slots of body with the MAC of the rest of the code.
+func (cf *CodeFactory) sign(slots []slot, body []string) {
+	mac := hmac.New(sha256.New, cf.key)
+	mac.Write([]byte(cf.prefix))
+	for j, s := range slots {
+		if s.verb != 's' && s.verb != 'r' {
+			mac.Write([]byte(body[j]))
+		}
+	}
+	mac.Write([]byte(cf.suffix))
+
+	// render the MAC in the set from the last signature character backwards
+	n := new(big.Int).SetBytes(mac.Sum(nil))
+	m := new(big.Int)
+	for j := len(slots) - 1; j >= 0; j-- {
+		if slots[j].verb != 's' {
+			continue
+		}
+		n.DivMod(n, big.NewInt(int64(len(slots[j].vals))), m)
+		body[j] = slots[j].vals[m.Int64()]
+	}
+}
+
+// checkSignature compares the signature slots of body with those expected
+// from the rest of the code in constant time.
+func (cf *CodeFactory) checkSignature(slots []slot, body []string) error {
+	if cf.key == nil {
+		return ErrNoKey
+	}
+	want := append([]string{}, body...)
+	cf.sign(slots, want)
+
+	got, exp := "", ""
+	for j, s := range slots {
+		if s.verb == 's' {
+			got += body[j]
+			exp += want[j]
+		}
+	}
+	if !hmac.Equal([]byte(got), []byte(exp)) {
+		return ErrBadSignature
+	}
+	return nil
+}
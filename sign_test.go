@@ -0,0 +1,120 @@
+package codefactory
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSign(t *testing.T) {
+
+	Convey("Signed codes validate with the key", t, func() {
+
+		cf := New()
+		cf.SetFormat("#xxxx-ss")
+		cf.SetKey([]byte("secret"))
+
+		codes, err := cf.Generate(100)
+		So(err, ShouldBeNil)
+
+		for _, code := range codes {
+			So(cf.Validate(code), ShouldBeNil)
+		}
+
+		Convey("but not with a different key", func() {
+			other := New()
+			other.SetFormat("#xxxx-ss")
+			other.SetKey([]byte("guess"))
+
+			failed := 0
+			for _, code := range codes {
+				if other.Validate(code) == ErrBadSignature {
+					failed++
+				}
+			}
+			So(failed, ShouldBeGreaterThan, 90)
+		})
+
+		Convey("or without a key", func() {
+			cf.SetKey(nil)
+			So(cf.Validate(codes[0]), ShouldEqual, ErrNoKey)
+		})
+	})
+
+	Convey("Tampered codes don't validate", t, func() {
+
+		cf := New()
+		cf.SetFormat("ddddsss")
+		cf.SetKey([]byte("secret"))
+
+		codes, err := cf.Generate(1)
+		So(err, ShouldBeNil)
+
+		code := []byte(codes[0])
+		code[0] = '0' + (code[0]-'0'+1)%10
+		So(cf.Validate(string(code)), ShouldEqual, ErrBadSignature)
+	})
+
+	Convey("Signing is deterministic", t, func() {
+
+		cf := New()
+		cf.SetFormat("dddd-ss")
+		cf.SetKey([]byte("secret"))
+
+		slots, body, err := cf.parse("1234-00")
+		So(err, ShouldBeNil)
+		cf.sign(slots, body)
+		signed := "1234-" + body[5] + body[6]
+
+		So(cf.Validate(signed), ShouldBeNil)
+	})
+
+	Convey("Generating signed codes requires a key", t, func() {
+
+		cf := New()
+		cf.SetFormat("dddd-ss")
+
+		res, err := cf.Generate(1)
+		So(err, ShouldEqual, ErrNoKey)
+		So(res, ShouldResemble, []string{})
+	})
+
+	Convey("Signature characters need a set", t, func() {
+
+		cf := New()
+		cf.SetFormat("cs")
+		cf.SetCustom("abc")
+		cf.SetKey([]byte("secret"))
+		cf.num, cf.lower, cf.upper = "", "", ""
+
+		res, err := cf.Generate(1)
+		So(err, ShouldEqual, ErrNoCharacters)
+		So(res, ShouldResemble, []string{})
+	})
+
+	Convey("Signature characters don't add to MaxCodes", t, func() {
+
+		cf := New()
+		cf.SetFormat("dd-ss")
+
+		So(cf.MaxCodes(), ShouldEqual, 100)
+	})
+}
+
+func TestForgeryProbability(t *testing.T) {
+
+	Convey("Forgery probability falls with the signature length", t, func() {
+
+		cf := New()
+		So(cf.ForgeryProbability(), ShouldEqual, 1)
+
+		cf.SetFormat("xxxx-s")
+		So(cf.ForgeryProbability(), ShouldAlmostEqual, 1.0/62)
+
+		cf.SetFormat("xxxx-sss")
+		So(cf.ForgeryProbability(), ShouldAlmostEqual, 1.0/(62*62*62))
+
+		cf.Exclude(defaultUppercase)
+		So(cf.ForgeryProbability(), ShouldAlmostEqual, 1.0/(36*36*36))
+	})
+}
@@ -0,0 +1,106 @@
+package codefactory
+
+import "unicode"
+
+// backslash is the escape character used by the ID constructors and Infer.
+const backslash = '\\'
+
+// fixedVerbs are the format characters that aren't filled at random.
+const fixedVerbs = "stnymeigr"
+
+// slot is a single position in the format of a code.
+type slot struct {
+	verb rune     // format character, or 0 for a literal
+	lit  string   // printed as is in a literal slot
+	vals []string // values that a code slot may take
+
+	field bool // holds part of the packed fields
+}
+
+// random reports whether the slot is filled with a random value.
+func (s slot) random() bool {
+	return s.verb != 0 && !isIncludedIn(fixedVerbs, s.verb) && !s.field
+}
+
+// SetEscape sets a punctuation or symbol character that makes the character
+// after it in the format a literal, so that letters and numbers can be printed
+// within the code, as in the format `xxxx-\4xxx` with a backslash as the
+// escape.  The escape character itself is printed by doubling it.
+//
+// There is no escape character by default, so that a format that was valid
+// before escapes existed keeps its meaning.  A 0 turns escapes off again.  An
+// error is returned if the current format isn't valid with the new escape.
+func (cf *CodeFactory) SetEscape(r rune) error {
+	if r != 0 && !unicode.IsPunct(r) && !unicode.IsSymbol(r) {
+		return charError(ErrInvalidFormat, string(r), 0)
+	}
+	if err := checkFormat(cf.format, r); err != nil {
+		return err
+	}
+	cf.escape = r
+	return nil
+}
+
+// slots splits the format of the CodeFactory into slots.
+func (cf *CodeFactory) slots() []slot {
+	res := []slot{}
+	escaped := false
+	for _, v := range cf.format {
+		if v == cf.escape && cf.escape != 0 && !escaped {
+			escaped = true
+			continue
+		}
+		// formatting symbol or escaped character
+		if !unicode.IsLetter(v) || escaped {
+			res = append(res, slot{lit: string(v)})
+			escaped = false
+			continue
+		}
+		vals := []string{}
+		for _, c := range cf.set(v) {
+			vals = append(vals, string(c))
+		}
+		if v == 'b' {
+			vals = cf.wordList()
+		}
+		res = append(res, slot{verb: v, vals: vals})
+	}
+	cf.markFieldSlots(res)
+	return res
+}
+
+// hasVerb reports whether the format contains the format character v.
+func (cf *CodeFactory) hasVerb(v rune) bool {
+	escaped := false
+	for _, r := range cf.format {
+		switch {
+		case escaped:
+			escaped = false
+		case r == cf.escape && cf.escape != 0:
+			escaped = true
+		case r == v:
+			return true
+		}
+	}
+	return false
+}
+
+// newBody returns the values of the slots that are the same for all the codes
+// of a batch: the literals, fields, time, and date.  The other slots are left
+// empty.
+func (cf *CodeFactory) newBody(slots []slot) ([]string, error) {
+	body := make([]string, len(slots))
+	for j, s := range slots {
+		// formatting symbol
+		if s.verb == 0 {
+			body[j] = s.lit
+		}
+	}
+	cf.packFields(slots, body)
+	now := cf.clock()
+	if err := cf.packTime(slots, body, now); err != nil {
+		return nil, err
+	}
+	packDate(slots, body, now)
+	return body, nil
+}
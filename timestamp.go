@@ -0,0 +1,113 @@
+package codefactory
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// defaultPrecision is the time precision used until SetTimePrecision is called.
+const defaultPrecision = time.Millisecond
+
+// SetTimePrecision sets the precision of the time held in the time characters
+// of the format, which defaults to a millisecond.  Coarser precisions need
+// fewer time characters to reach far into the future.
+//
+// All the codes of a batch hold the time at which the batch was started, in
+// the same way as fields, so the time characters don't count towards
+// MaxCodes.  Generating fails with ErrTimeOverflow once the time no longer
+// fits.
+func (cf *CodeFactory) SetTimePrecision(d time.Duration) error {
+	if d <= 0 {
+		return ErrTimePrecision
+	}
+	cf.precision = d
+	return nil
+}
+
+// Time returns the time embedded in the time characters of a canonical code,
+// truncated to the time precision.  If the format contains signature
+// characters, the signature is checked first.  ErrPolicySet is returned while
+// a policy is set.
+func (cf *CodeFactory) Time(code string) (time.Time, error) {
+	if cf.policy != nil {
+		return time.Time{}, ErrPolicySet
+	}
+	if !cf.timed() {
+		return time.Time{}, ErrNoTime
+	}
+	slots, body, err := cf.parse(code)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if cf.signed() {
+		if err := cf.checkSignature(slots, body); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	v := int64(0)
+	for j, s := range slots {
+		if s.verb != 't' {
+			continue
+		}
+		n, d := int64(len(s.vals)), int64(indexOf(s.vals, body[j]))
+		if v > (math.MaxInt64/int64(cf.timePrecision())-d)/n {
+			return time.Time{}, ErrInvalidCode
+		}
+		v = v*n + d
+	}
+	return time.Unix(0, v*int64(cf.timePrecision())), nil
+}
+
+// timed reports whether the format contains time characters.
+func (cf *CodeFactory) timed() bool {
+	return cf.hasVerb('t')
+}
+
+func (cf *CodeFactory) timePrecision() time.Duration {
+	if cf.precision == 0 {
+		return defaultPrecision
+	}
+	return cf.precision
+}
+
+// clock returns the current time.
+func (cf *CodeFactory) clock() time.Time {
+	if cf.now != nil {
+		return cf.now()
+	}
+	return time.Now()
+}
+
+// packTime fills the time slots of body with the time `t`, with the most
+// significant part first so that codes sort by time.
+func (cf *CodeFactory) packTime(slots []slot, body []string, t time.Time) error {
+	if !cf.timed() {
+		return nil
+	}
+	v := t.UnixNano() / int64(cf.timePrecision())
+	if v < 0 {
+		return ErrTimeOverflow
+	}
+	for j := len(slots) - 1; j >= 0; j-- {
+		if slots[j].verb != 't' {
+			continue
+		}
+		n := int64(len(slots[j].vals))
+		body[j] = slots[j].vals[v%n]
+		v /= n
+	}
+	if v > 0 {
+		return ErrTimeOverflow
+	}
+	return nil
+}
+
+// sortedSet returns the characters of s in order, so that codes made up of
+// them sort in the same order as the values they hold.
+func sortedSet(s string) string {
+	r := []rune(s)
+	sort.Slice(r, func(i, j int) bool { return r[i] < r[j] })
+	return string(r)
+}
@@ -0,0 +1,105 @@
+package codefactory
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTimeCodes(t *testing.T) {
+	var testCases = []struct {
+		desc      string
+		format    string
+		precision time.Duration
+		extend    string
+		exclude   string
+		want      time.Time
+	}{
+		{
+			desc:   "milliseconds by default",
+			format: "tttttttt-xxxx",
+			want:   time.Unix(1500000000, 123000000),
+		},
+		{
+			desc:      "coarse precision",
+			format:    "tttt-dddd",
+			precision: time.Hour,
+			want:      time.Unix(1500000000, 0).Truncate(time.Hour),
+		},
+		{
+			desc:    "restricted and extended sets",
+			format:  "ttttttttt ww",
+			extend:  "ÄÖÜäöü",
+			exclude: "ilo01IO",
+			want:    time.Unix(1500000000, 123000000),
+		},
+	}
+
+	for i, tt := range testCases {
+		Convey(fmt.Sprintf("Case # %d: %s", i, tt.desc), t, func() {
+
+			cf := New()
+			cf.SetFormat(tt.format)
+			cf.ExtendLetters(tt.extend)
+			cf.Exclude(tt.exclude)
+			if tt.precision != 0 {
+				cf.SetTimePrecision(tt.precision)
+			}
+
+			// codes from later batches sort after those from earlier ones
+			codes := []string{}
+			for k := 0; k < 5; k++ {
+				now := time.Unix(1500000000, 123456789).Add(time.Duration(k) * 1000 * time.Hour)
+				cf.now = func() time.Time { return now }
+
+				res, err := cf.Generate(10)
+				So(err, ShouldBeNil)
+				sort.Strings(res)
+				codes = append(codes, res...)
+			}
+			So(sort.StringsAreSorted(codes), ShouldBeTrue)
+
+			got, err := cf.Time(codes[0])
+			So(err, ShouldBeNil)
+			So(got.Equal(tt.want), ShouldBeTrue)
+			So(cf.Validate(codes[0]), ShouldBeNil)
+		})
+	}
+
+	Convey("Time characters aren't random", t, func() {
+
+		cf := New()
+		cf.SetFormat("ttttttt-dd")
+		So(cf.MaxCodes(), ShouldEqual, 100)
+
+		res, err := cf.Generate(100)
+		So(err, ShouldBeNil)
+		So(len(res), ShouldEqual, 100)
+	})
+
+	Convey("Times that don't fit are rejected", t, func() {
+
+		cf := New()
+		cf.SetFormat("tt-dd")
+
+		_, err := cf.Generate(10)
+		So(err, ShouldEqual, ErrTimeOverflow)
+
+		So(cf.SetTimePrecision(0), ShouldEqual, ErrTimePrecision)
+		So(cf.SetTimePrecision(365*24*time.Hour), ShouldBeNil)
+		_, err = cf.Generate(10)
+		So(err, ShouldBeNil)
+	})
+
+	Convey("Time needs time characters", t, func() {
+
+		cf := New()
+		cf.SetFormat("dd")
+
+		_, err := cf.Time("12")
+		So(err, ShouldEqual, ErrNoTime)
+	})
+}
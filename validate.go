@@ -0,0 +1,66 @@
+package codefactory
+
+import "strings"
+
+// Validate checks that the code could have been generated by the CodeFactory
+// with its current settings.  If the format contains parity characters, they
+// are checked against the other code characters, and if it contains signature
+// characters, the signature is checked against the key.  If a policy is set,
+// the code is checked against the policy instead of the format.  Reserved
+// codes are valid, as they are only kept back from generation.
+//
+// Validate expects codes in their canonical form, so codes entered by people
+// should first be passed through Normalize.
+func (cf *CodeFactory) Validate(code string) error {
+	if cf.policy != nil {
+		return cf.validatePolicy(code)
+	}
+	slots, body, err := cf.parse(code)
+	if err != nil {
+		return err
+	}
+	if rs, err := cf.reedSolomon(slots); err != nil {
+		return err
+	} else if rs != nil && !rs.check(slots, body) {
+		return ErrBadParity
+	}
+	if cf.signed() {
+		return cf.checkSignature(slots, body)
+	}
+	return nil
+}
+
+// parse splits a canonical code into the values of each slot of the format.
+func (cf *CodeFactory) parse(code string) ([]slot, []string, error) {
+	if len(code) < len(cf.prefix)+len(cf.suffix) ||
+		!strings.HasPrefix(code, cf.prefix) || !strings.HasSuffix(code, cf.suffix) {
+		return nil, nil, ErrInvalidCode
+	}
+	rest := code[len(cf.prefix) : len(code)-len(cf.suffix)]
+
+	slots := cf.slots()
+	body := make([]string, len(slots))
+	if !matchSlots(slots, body, rest) {
+		return nil, nil, ErrInvalidCode
+	}
+	return slots, body, nil
+}
+
+// matchSlots fills body with the values of the slots that make up s.  Each
+// value that s starts with is tried in turn, as a word may start with another.
+func matchSlots(slots []slot, body []string, s string) bool {
+	if len(slots) == 0 {
+		return s == ""
+	}
+	vals := slots[0].vals
+	if slots[0].verb == 0 {
+		vals = []string{slots[0].lit}
+	}
+	for _, v := range vals {
+		if strings.HasPrefix(s, v) && matchSlots(slots[1:], body[1:], s[len(v):]) {
+			body[0] = v
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package codefactory
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValidate(t *testing.T) {
+	var testCases = []struct {
+		desc    string
+		format  string
+		prefix  string
+		suffix  string
+		input   string
+		wantErr error
+	}{
+		{
+			desc:   "valid code",
+			format: "#ll-dd",
+			input:  "#ab-12",
+		},
+		{
+			desc:   "valid code with prefix and suffix",
+			format: "ll-dd",
+			prefix: "红 ",
+			suffix: " end",
+			input:  "红 ab-12 end",
+		},
+		{
+			desc:    "missing prefix",
+			format:  "ll-dd",
+			prefix:  "红 ",
+			input:   "ab-12",
+			wantErr: ErrInvalidCode,
+		},
+		{
+			desc:    "missing suffix",
+			format:  "ll-dd",
+			suffix:  " end",
+			input:   "ab-12",
+			wantErr: ErrInvalidCode,
+		},
+		{
+			desc:    "prefix and suffix overlap",
+			format:  "",
+			prefix:  "ab",
+			suffix:  "bc",
+			input:   "abc",
+			wantErr: ErrInvalidCode,
+		},
+		{
+			desc:    "wrong literal",
+			format:  "#ll-dd",
+			input:   "#ab+12",
+			wantErr: ErrInvalidCode,
+		},
+		{
+			desc:    "character not in set",
+			format:  "#ll-dd",
+			input:   "#aB-12",
+			wantErr: ErrInvalidCode,
+		},
+		{
+			desc:    "too long",
+			format:  "#ll-dd",
+			input:   "#ab-123",
+			wantErr: ErrInvalidCode,
+		},
+		{
+			desc:    "too short",
+			format:  "#ll-dd",
+			input:   "#ab-1",
+			wantErr: ErrInvalidCode,
+		},
+	}
+
+	for i, tt := range testCases {
+		Convey(fmt.Sprintf("Case # %d: %s", i, tt.desc), t, func() {
+
+			cf := New()
+			cf.format = tt.format
+			So(cf.SetPrefix(tt.prefix), ShouldBeNil)
+			So(cf.SetSuffix(tt.suffix), ShouldBeNil)
+
+			So(cf.Validate(tt.input), ShouldEqual, tt.wantErr)
+		})
+	}
+
+	Convey("Generated codes with extended letters validate", t, func() {
+
+		cf := New()
+		cf.SetFormat("ll-uu")
+		cf.ExtendLetters("ñßÀÁ")
+		So(cf.MaxCodes(), ShouldEqual, 28*28*28*28)
+
+		codes, err := cf.Generate(100)
+		So(err, ShouldBeNil)
+
+		for _, code := range codes {
+			So(cf.Validate(code), ShouldBeNil)
+		}
+	})
+}
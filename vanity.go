@@ -0,0 +1,225 @@
+package codefactory
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// vanityFit is a position in the format at which a vanity substring fits.
+type vanityFit struct {
+	pins  []string // the value of each slot covered by the substring
+	space int64    // number of ways to fill the other random slots
+}
+
+// SetReserved sets codes that Generate, Vanity, and Iterator never return,
+// such as codes kept back to be handed out by hand.  The codes are matched
+// exactly, so they should be given in their canonical form, with the prefix
+// and suffix.  Codes that are drawn at random and turn out to be reserved are
+// discarded like duplicates.  Validate still accepts reserved codes, so that
+// they can be checked once they are handed out.  An empty list clears the
+// reserved codes.
+func (cf *CodeFactory) SetReserved(codes []string) {
+	if len(codes) == 0 {
+		cf.reserved = nil
+		return
+	}
+	cf.reserved = map[string]bool{}
+	for _, c := range codes {
+		cf.reserved[c] = true
+	}
+}
+
+// reservedCount returns the number of reserved codes that could be
+// generated with the current settings.
+func (cf *CodeFactory) reservedCount() int64 {
+	n := int64(0)
+	for c := range cf.reserved {
+		if cf.Validate(c) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Vanity generates `num` codes in the format of the CodeFactory that contain
+// the substring `sub`, such as "SALE" in "#SALE-7Q2".  The substring may be
+// placed wherever each of its characters is either a literal of the format,
+// or in the set of a random code character, in either case unless the
+// CodeFactory is case sensitive.  It can't cover signature, time, counter,
+// date, parity, or field characters.
+//
+// If positions are given, the substring only starts at those positions,
+// counted in runes from the start of the code, including the prefix.  The
+// codes are spread evenly over the codes that contain the substring, and are
+// discarded like duplicates if they are reserved, blocked, or too close to
+// each other.
+//
+// ErrNoVanity is returned if the substring is empty or doesn't fit anywhere,
+// or if the format has words, and ErrPolicySet while a policy is set.
+func (cf *CodeFactory) Vanity(sub string, num int, positions ...int) ([]string, error) {
+	if cf.policy != nil {
+		return []string{}, ErrPolicySet
+	}
+	if sub == "" || cf.hasVerb('b') {
+		return []string{}, ErrNoVanity
+	}
+	if err := cf.check(0); err != nil {
+		return []string{}, err
+	} else if num < 0 {
+		return []string{}, ErrInvalidCount
+	}
+
+	slots := cf.slots()
+	fits := cf.vanityFits(slots, []rune(sub), positions)
+	if len(fits) == 0 {
+		return []string{}, ErrNoVanity
+	}
+	space := int64(0)
+	for _, f := range fits {
+		if space > math.MaxInt64-f.space {
+			space = math.MaxInt64
+		} else {
+			space += f.space
+		}
+	}
+	if int64(num) > space {
+		return []string{}, &CountError{Requested: int64(num), Achievable: space}
+	}
+
+	body, err := cf.newBody(slots)
+	if err != nil {
+		return []string{}, err
+	}
+	rs, _ := cf.reedSolomon(slots)
+	signed, counted := cf.signed(), cf.counted()
+	near := cf.newNear()
+
+	rng := cf.rng()
+	maxRetries := cf.maxRetries(num, space)
+	dups := 0
+	res := map[string]bool{}
+	for i := 1; i <= num; i++ {
+
+		// pick a position in proportion to the number of codes that have the
+		// substring there
+		n := rng.Int63n(space)
+		f := fits[len(fits)-1]
+		for _, g := range fits {
+			if n < g.space {
+				f = g
+				break
+			}
+			n -= g.space
+		}
+		for j, s := range slots {
+			if f.pins[j] != "" {
+				body[j] = f.pins[j]
+			} else if s.random() {
+				body[j] = s.vals[rng.Intn(len(s.vals))]
+			}
+		}
+		if counted {
+			packCounter(slots, body, cf.counter+int64(i-1)*cf.counterStep())
+		}
+		if signed {
+			cf.sign(slots, body)
+		}
+		if rs != nil {
+			rs.encode(slots, body)
+		}
+
+		r := cf.prefix + strings.Join(body, "") + cf.suffix
+		var code []rune
+		if near != nil {
+			code = []rune(strings.Join(body, ""))
+		}
+		if res[r] || cf.reserved[r] || (near != nil && near.near(code)) || cf.blocked(body) {
+			i-- // generate a new code
+			dups++
+			if dups > maxRetries {
+				return []string{}, ErrMaxRetriesExceeded
+			}
+			continue
+		}
+		res[r] = true
+		if near != nil {
+			near.add(code)
+		}
+	}
+	if counted {
+		cf.counter += int64(num) * cf.counterStep()
+	}
+
+	codes := []string{}
+	for k := range res {
+		codes = append(codes, k)
+	}
+	return codes, nil
+}
+
+// vanityFits returns the positions in the format at which the substring
+// fits, limited to the given positions if there are any.
+func (cf *CodeFactory) vanityFits(slots []slot, sub []rune, positions []int) []vanityFit {
+	fits := []vanityFit{}
+	offset := len([]rune(cf.prefix))
+	positions = append([]int{}, positions...)
+	sort.Ints(positions)
+
+	for start := 0; start+len(sub) <= len(slots); start++ {
+		if len(positions) > 0 {
+			k := sort.SearchInts(positions, offset+start)
+			if k == len(positions) || positions[k] != offset+start {
+				continue
+			}
+		}
+
+		f := vanityFit{pins: make([]string, len(slots)), space: 1}
+		ok := true
+		for k, r := range sub {
+			j := start + k
+			v, found := cf.vanityValue(slots[j], r)
+			if !found {
+				ok = false
+				break
+			}
+			if slots[j].verb != 0 {
+				f.pins[j] = v
+			}
+		}
+		if !ok {
+			continue
+		}
+		for j, s := range slots {
+			if s.random() && f.pins[j] == "" {
+				f.space = mulCapped(f.space, int64(len(s.vals)))
+			}
+		}
+		fits = append(fits, f)
+	}
+	return fits
+}
+
+// vanityValue returns the value of the slot that the character r of a vanity
+// substring stands for, and false if the slot can't hold it.
+func (cf *CodeFactory) vanityValue(s slot, r rune) (string, bool) {
+	vals := []string{s.lit}
+	if s.verb != 0 {
+		if !s.random() {
+			return "", false
+		}
+		vals = s.vals
+	}
+
+	variants := []rune{r}
+	if !cf.strictCase {
+		variants = append(variants, unicode.ToUpper(r), unicode.ToLower(r))
+	}
+	for _, v := range variants {
+		if indexOf(vals, string(v)) >= 0 {
+			return string(v), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,164 @@
+package codefactory
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReserved(t *testing.T) {
+
+	Convey("Reserved codes are never generated", t, func() {
+
+		cf := New()
+		cf.SetFormat("dd")
+		cf.SetSeed(1)
+		cf.SetReserved([]string{"07", "42", "SALE"})
+		So(cf.MaxCodes(), ShouldEqual, 98)
+
+		res, err := cf.Generate(98)
+		So(err, ShouldBeNil)
+		So(len(res), ShouldEqual, 98)
+		So(res, ShouldNotContain, "07")
+		So(res, ShouldNotContain, "42")
+
+		_, err = cf.Generate(99)
+		So(err, ShouldResemble, &CountError{Requested: 99, Achievable: 98})
+	})
+
+	Convey("Reserved codes are part of the fingerprint", t, func() {
+
+		cf := New()
+		fp := cf.Fingerprint()
+		cf.SetReserved([]string{"#SALE"})
+		So(cf.Fingerprint(), ShouldNotEqual, fp)
+		cf.SetReserved(nil)
+		So(cf.Fingerprint(), ShouldEqual, fp)
+	})
+}
+
+func TestVanity(t *testing.T) {
+	var testCases = []struct {
+		desc      string
+		cf        *CodeFactory
+		format    string
+		sub       string
+		positions []int
+		want      string // pattern that every code matches
+		err       error
+	}{
+		{
+			desc:   "anywhere it fits",
+			cf:     New(),
+			format: "xxxx-xxxx",
+			sub:    "SALE",
+			want:   `^#(SALE-....|....-SALE)$`,
+		},
+		{
+			desc:   "across a literal",
+			cf:     New(),
+			format: "xxxx-xxxx",
+			sub:    "LE-7",
+			want:   `^#..LE-7...$`,
+		},
+		{
+			desc:      "at a given position",
+			cf:        New(),
+			format:    "xxxx-xxxx",
+			sub:       "SALE",
+			positions: []int{1, 3},
+			want:      `^#SALE-....$`,
+		},
+		{
+			desc:   "case folded into the set",
+			cf:     New(),
+			format: "wwwwww",
+			sub:    "SALE",
+			want:   `sale`,
+		},
+		{
+			desc:   "case sensitive preset",
+			cf:     NewBase58(),
+			format: "xxxxxx",
+			sub:    "sale",
+			err:    ErrNoVanity,
+		},
+		{
+			desc:   "not in the sets",
+			cf:     New(),
+			format: "dddd",
+			sub:    "SALE",
+			err:    ErrNoVanity,
+		},
+		{
+			desc:      "no fit at the given positions",
+			cf:        New(),
+			format:    "xxxx-xxxx",
+			sub:       "SALE",
+			positions: []int{2},
+			err:       ErrNoVanity,
+		},
+		{
+			desc:   "not over signature characters",
+			cf:     New(),
+			format: "xxxss",
+			sub:    "bc",
+			want:   `^#(bc.|.bc)..$`,
+		},
+		{
+			desc:      "only over signature characters",
+			cf:        New(),
+			format:    "xxxss",
+			sub:       "bc",
+			positions: []int{3},
+			err:       ErrNoVanity,
+		},
+	}
+
+	for i, tt := range testCases {
+		Convey(fmt.Sprintf("Case # %d: %s", i, tt.desc), t, func() {
+
+			cf := tt.cf
+			cf.SetPrefix("#")
+			cf.SetKey([]byte("key"))
+			So(cf.SetFormat(tt.format), ShouldBeNil)
+			cf.SetSeed(1)
+
+			res, err := cf.Vanity(tt.sub, 20, tt.positions...)
+			So(err, ShouldEqual, tt.err)
+			if err != nil {
+				return
+			}
+			So(len(res), ShouldEqual, 20)
+			re := regexp.MustCompile(tt.want)
+			for _, code := range res {
+				So(re.MatchString(code), ShouldBeTrue)
+				So(cf.Validate(code), ShouldBeNil)
+			}
+		})
+	}
+
+	Convey("Vanity codes skip reserved codes and count the codes that fit", t, func() {
+
+		cf := New()
+		cf.SetFormat("dd")
+		cf.SetSeed(1)
+		cf.SetReserved([]string{"77"})
+
+		res, err := cf.Vanity("7", 9, 0)
+		So(err, ShouldBeNil)
+		So(len(res), ShouldEqual, 9)
+		So(res, ShouldNotContain, "77")
+
+		_, err = cf.Vanity("7", 11, 0)
+		So(err, ShouldResemble, &CountError{Requested: 11, Achievable: 10})
+
+		_, err = cf.Vanity("", 1)
+		So(err, ShouldEqual, ErrNoVanity)
+
+		_, err = cf.Vanity("7", -1)
+		So(err, ShouldEqual, ErrInvalidCount)
+	})
+}
@@ -0,0 +1,100 @@
+package codefactory
+
+import "strings"
+
+// defaultWords is the word list used by 'b' until SetWords is called.  It
+// holds 1024 short, common English words, so each word adds 10 bits to a code.
+var defaultWords = strings.Fields(`
+able acid acorn acre acrobat actor admiral adult agent airship aisle
+alarm album alcove alert alien alley almond alpha amber amulet anchor
+angle ankle antler anvil apple apricot april apron arcade arch arena
+argue armchair army aroma arrow art ash asphalt atlas atom attic aunt
+autumn avid avocado award axis baby bacon badge bagel bagpipe baker
+ballet bamboo banana band banjo bank banner barley barn baron barrel
+basalt basil basin basket bath beach beacon beam bean beard beast
+beaver bed beef beetle beetroot bell belt bench berry bike biplane
+bird biscuit bison black blade blanket blast blaze blend blimp blink
+bliss blizzard block bloom blossom blue blunt blur board boat bobcat
+body bolt bone bonfire bonnet bonus book boost booth border boss
+bottle bouquet bowl box bracket brain brand brass brave bread break
+breeze brick bride bridge brief bright brisk broad brook broom brown
+brush bubble bucket buckle buddy budget buffalo buffer bugle build
+bulb bull bunch bundle bunny burrito burst bush butter button buzz
+cabin cable cactus cafe cage cake calm camel camera camper canal
+candle candy cane canoe canopy canvas canyon cape caramel card cargo
+carol carpet carrot cart case cashew castle cat cedar cellar cello
+cement chain chair chalk champ chapel charm chart chase cheek cheese
+cheetah cherry chess chest chick chief child chili chime chimney chip
+choir chord chrome cider cinder circle citrus city civic clam clap
+class claw clay clean clerk click cliff climb clock cloth cloud clover
+clown club coach coast coat cobalt cobra cobweb cocoa coconut code
+coffee coin cola comb comet comic compass condor copper coral cord
+core corn cotton couch cougar count court cousin cover cowboy coyote
+crab cradle craft crane crater crayon cream creek crew cricket crisp
+crop cross crown cruise crumb crust cube cupcake cupid curl curry
+curve cycle dahlia daisy dance dart dash data dawn day deck deer delta
+denim depth desk dial diamond diary diner dinghy dinner disk diver
+dock doctor dog doll dolphin domino donkey door dough dove draft
+dragon drama dream dress drift drill drink drum duck dune dust eagle
+early earth easel echo eclipse edge eel egg elbow elder elk elm ember
+emblem emerald emu energy engine equal event exam exit fabric face
+fact fairy falcon fame fan farm fashion feast feather fence fennel
+fern ferret ferry fiddle field fig film finch finger fire fish fjord
+flag flame flannel flash flask fleet flint float flock flood floor
+flour flower flute foam focus fog folk font food foot forest fork fort
+fossil fox frame fresh frog frost fruit fudge fuel funnel fur gadget
+galaxy game garden garlic garnet gate gazebo gear gecko gem genie
+geyser ghost giant gift ginger giraffe glacier glad glass globe glove
+glow glue goat goblet gold golf goose gorilla grain granite grape
+graph grass gravel gravy great green grid grill grin grove guard guest
+guide guitar gull gum guppy habit hair hamlet hammer hammock hand
+harbor harp harvest hat hawk hazel head heart heat hedge helium helmet
+hen herb heron hiking hill hinge hippo hobby hockey honey hood hook
+hope horn horse host hotel hound house hub hug human humor husky hut
+iceberg icon idea igloo image inch index ink inlet input insect iris
+iron island ivory ivy jacket jade jaguar jam jar jasmine jazz jeans
+jelly jet jewel jigsaw job jog joke journal joy judge juice jumbo
+jungle kayak kernel kettle key kid king kite kitten kiwi knee knife
+knob knot koala label lace ladder ladle lady lagoon lake lamb lamp
+lane lantern laser latch lawn layer leaf legend lemon lemur lens
+lettuce level lever light lilac lily lime linen lion lizard llama
+lobby lobster locket lodge logic loop lotus lucky lumber lunar lunch
+lynx magic magnet maize mammoth mango manor maple marble march market
+marlin marsh mask match meadow medal melon menu metal meteor meter
+mint mirror mist mitten mixer model molar money monkey month moon
+moose mosaic moss motor mouse mouth mud muffin mug mule mural music
+mustard nail name napkin navy nebula neck nectar needle nest net
+nickel night noble noodle north nose note novel nugget nurse nutmeg
+oak oasis oatmeal ocean octave office olive omega onion opal opera
+orange orbit orchard orchid organ otter ounce oven owl oyster paddle
+page paint palace palm panda panel paper paprika parade park parrot
+parsley party pasta pastry patch path peach peak peanut pearl pebble
+pecan pedal pelican pencil penny pepper piano pickle picnic pier
+pigeon pillow pilot pine pink pipe pirate pizza planet plant plate
+plaza plum pocket poem polar pond pony pool poppy porch port potato
+pouch powder pretzel prism prize puffin pumpkin puppy puzzle quail
+quartz queen quest quick quiet quilt quiver quiz rabbit raccoon radar
+radio radish raft rainbow raisin rake ranch raven ravine razor recipe
+reef reindeer relay remote rhino ribbon rice riddle ridge ring river
+road robin robot rocket rodeo roof room root rope rose rover royal
+ruby rug ruler rumba saddle safari saffron sail salad salmon salsa
+salt sandal sapphire satin sauce scarf school scout screen seal season
+seed sequin shadow shark shelf shell sherbet shield ship shirt shoe
+shore shovel show silk silver sing siren sister skate skillet sky sled
+sleep sleigh slope smile snack snail snake snow soap soccer sock sofa
+solar song soup space spark sparrow spice spider spoon sport spring
+sprout spruce square squid stable stage stair stamp star statue steam
+stencil stone stool storm story stove straw stream street string sugar
+summer summit sunset swan sweater swing sword syrup table taco tadpole
+tail talent tango tank tape target taxi teacher team teapot teddy
+tennis tent thimble thistle thread thumb ticket tiger timber tinsel
+toast today token tomato tool tooth topaz torch toucan tower town toy
+track tractor trail train tree trick trolley trophy truck trumpet
+tulip tuna tundra tunnel turkey turnip turtle tuxedo twig umbrella
+uncle unicorn union unit urban vacuum valley van vase velvet vessel
+vest video view village vine violin visa voice volcano voyage waffle
+wagon walnut walrus wand water wave wax whale wheat wheel whistle
+willow window wing winter wizard wolf wombat wood wool world yacht yak
+yard yarn yoga yogurt zebra zephyr zero zigzag zinc zipper zone
+zucchini
+`)
@@ -0,0 +1,96 @@
+package codefactory
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SetWords sets the word list used by the word characters ('b') of the
+// format, so that codes such as "apple-river-42" can be generated with the
+// format "b-b-dd".  Each word adds log2(len(words)) bits to a code, which
+// Entropy reports.
+//
+// The words must be made of letters, and be distinct ignoring case, so that
+// Normalize can read them back.  Words may start with other words, as long as
+// the format separates its word characters with something other than letters:
+// "b-b" can be generated with any list, but "bb" can't be with a list that
+// has "bag", "bagel", "bow", and "elbow".  An empty list restores the bundled
+// list of 1024 common English words, in which no word starts with another.
+func (cf *CodeFactory) SetWords(words []string) error {
+	seen := map[string]bool{}
+	for _, w := range words {
+		if w == "" || firstRune(w, func(v rune) bool { return !unicode.IsLetter(v) }) >= 0 {
+			return ErrInvalidWord
+		}
+		if seen[strings.ToLower(w)] {
+			return ErrInvalidWord
+		}
+		seen[strings.ToLower(w)] = true
+	}
+	if len(words) == 0 {
+		cf.words = nil
+		return nil
+	}
+	cf.words = append([]string{}, words...)
+	return nil
+}
+
+// wordList returns the words that the word characters may be replaced with.
+func (cf *CodeFactory) wordList() []string {
+	if cf.words == nil {
+		return defaultWords
+	}
+	return cf.words
+}
+
+// wordsApart reports whether the words of every code can be told apart, as
+// either no word in the list starts with another, or the format separates
+// each pair of word characters with a slot that can't hold a letter.
+func (cf *CodeFactory) wordsApart(slots []slot) bool {
+	if cf.words == nil || prefixFree(cf.words) {
+		return true
+	}
+	joined := false // a word character since the last separator
+	for _, s := range slots {
+		vals := s.vals
+		if s.verb == 0 {
+			vals = []string{s.lit}
+		}
+		switch {
+		case s.verb == 'b' && joined:
+			return false
+		case s.verb == 'b':
+			joined = true
+		case firstRune(strings.Join(vals, ""), unicode.IsLetter) < 0:
+			joined = false
+		}
+	}
+	return true
+}
+
+// prefixFree reports whether no word starts with another, ignoring case.
+func prefixFree(words []string) bool {
+	lower := make([]string, len(words))
+	for i, w := range words {
+		lower[i] = strings.ToLower(w)
+	}
+	// a word that starts with another comes straight after it, or after
+	// another word that does
+	sort.Strings(lower)
+	for i := 1; i < len(lower); i++ {
+		if strings.HasPrefix(lower[i], lower[i-1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameWord reports whether the typed word matches w, ignoring case unless the
+// CodeFactory is case sensitive.
+func (cf *CodeFactory) sameWord(typed, w string) bool {
+	if cf.strictCase {
+		return typed == w
+	}
+	return strings.EqualFold(typed, w)
+}
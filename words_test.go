@@ -0,0 +1,216 @@
+package codefactory
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWords(t *testing.T) {
+
+	Convey("Codes are made of words from the bundled list", t, func() {
+
+		cf := New()
+		So(cf.SetFormat("b-b"), ShouldBeNil)
+		cf.SetSeed(1)
+		So(cf.MaxCodes(), ShouldEqual, 1024*1024)
+		So(cf.Entropy(), ShouldEqual, 20)
+
+		res, rep, err := cf.GenerateWithReport(1000)
+		So(err, ShouldBeNil)
+		So(rep.Entropy, ShouldEqual, cf.Entropy())
+
+		words := map[string]bool{}
+		for _, w := range defaultWords {
+			words[w] = true
+		}
+		re := regexp.MustCompile(`^([a-z]+)-([a-z]+)$`)
+		for _, code := range res {
+			m := re.FindStringSubmatch(code)
+			So(m, ShouldNotBeNil)
+			So(words[m[1]] && words[m[2]], ShouldBeTrue)
+			So(cf.Validate(code), ShouldBeNil)
+		}
+	})
+
+	Convey("A custom word list", t, func() {
+
+		cf := New()
+		cf.SetFormat("b-b")
+		So(cf.SetWords([]string{"red", "green", "blue"}), ShouldBeNil)
+		So(cf.MaxCodes(), ShouldEqual, 9)
+		So(cf.Entropy(), ShouldAlmostEqual, math.Log2(9))
+
+		res, err := cf.Generate(9)
+		So(err, ShouldBeNil)
+		So(res, ShouldContain, "green-blue")
+		So(cf.Validate("red-red"), ShouldBeNil)
+		So(cf.Validate("red-pink"), ShouldEqual, ErrInvalidCode)
+		So(cf.Validate("red-re"), ShouldEqual, ErrInvalidCode)
+
+		fp := cf.Fingerprint()
+		So(cf.SetWords(nil), ShouldBeNil)
+		So(cf.MaxCodes(), ShouldEqual, 1024*1024)
+		So(cf.Fingerprint(), ShouldNotEqual, fp)
+	})
+
+	Convey("Invalid word lists are rejected", t, func() {
+
+		cf := New()
+		for _, words := range [][]string{{"red", "Red"}, {"re d"}, {"r3d"}, {"red", ""}} {
+			So(cf.SetWords(words), ShouldEqual, ErrInvalidWord)
+		}
+		So(cf.wordList(), ShouldResemble, defaultWords)
+	})
+
+	Convey("No two pairs of bundled words join into the same code", t, func() {
+
+		joined := make(map[string]bool, len(defaultWords)*len(defaultWords))
+		for _, a := range defaultWords {
+			for _, b := range defaultWords {
+				joined[a+b] = true
+			}
+		}
+		So(len(joined), ShouldEqual, len(defaultWords)*len(defaultWords))
+		So(prefixFree(defaultWords), ShouldBeTrue)
+	})
+
+	Convey("Words that start with other words must be separated", t, func() {
+
+		cf := New()
+		cf.SetWords([]string{"bag", "bagel", "bow", "elbow"})
+		cf.SetEscape(backslash)
+		for _, format := range []string{"bb", "b\\bb", "blb"} {
+			cf.SetFormat(format)
+			_, err := cf.Generate(1)
+			So(err, ShouldEqual, ErrWordsJoined)
+		}
+		for _, format := range []string{"b-b", "bdb", "bl-b", "b-lb"} {
+			cf.SetFormat(format)
+			_, err := cf.Generate(1)
+			So(err, ShouldBeNil)
+		}
+
+		cf.SetWords([]string{"bag", "elbow", "bow"})
+		cf.SetFormat("bb")
+		So(cf.MaxCodes(), ShouldEqual, 9)
+		res, err := cf.Generate(9)
+		So(err, ShouldBeNil)
+		So(res, ShouldContain, "bagelbow")
+	})
+
+	Convey("Words that start with other words are validated", t, func() {
+
+		cf := New()
+		cf.SetFormat("bb")
+		cf.SetWords([]string{"car", "card", "ink", "kink"})
+
+		for _, code := range []string{"cardink", "carkink", "cardkink", "carink"} {
+			So(cf.Validate(code), ShouldBeNil)
+		}
+		So(cf.Validate("cardinal"), ShouldEqual, ErrInvalidCode)
+	})
+
+	Convey("Codes with words are kept apart", t, func() {
+
+		cf := New()
+		cf.SetFormat("b-b")
+		cf.SetSeed(1)
+		cf.SetWords([]string{"ox", "cat", "bat", "dog", "horse", "mouse"})
+		cf.SetMinDistance(2, DamerauLevenshtein)
+
+		res, err := cf.Generate(int(cf.MaxCodes()))
+		So(err, ShouldBeNil)
+		for i, a := range res {
+			for _, b := range res[:i] {
+				So(DamerauLevenshtein.Distance(a, b), ShouldBeGreaterThanOrEqualTo, 2)
+			}
+		}
+	})
+
+	Convey("Formats with words can't have parity characters", t, func() {
+
+		cf := New()
+		cf.SetFormat("b-rr")
+		cf.SetWords([]string{"red", "green", "blue"})
+		_, err := cf.Generate(1)
+		So(err, ShouldEqual, ErrNoField)
+	})
+}
+
+func TestNormalizeWords(t *testing.T) {
+	var testCases = []struct {
+		desc   string
+		format string
+		words  []string
+		input  string
+		want   string
+		err    error
+	}{
+		{
+			desc:   "mixed case and spaces",
+			format: "b-b-dd",
+			words:  []string{"apple", "river", "stone"},
+			input:  " Apple River 42",
+			want:   "apple-river-42",
+		},
+		{
+			desc:   "without separators",
+			format: "b-b-dd",
+			words:  []string{"apple", "river", "stone"},
+			input:  "STONEAPPLE07",
+			want:   "stone-apple-07",
+		},
+		{
+			desc:   "look-alikes outside the words",
+			format: "b-b-dd",
+			words:  []string{"apple", "river", "stone"},
+			input:  "apple.river.4O",
+			want:   "apple-river-40",
+		},
+		{
+			desc:   "word not in the list",
+			format: "b-b-dd",
+			words:  []string{"apple", "river", "stone"},
+			input:  "apple-rivers-42",
+			err:    ErrInvalidCode,
+		},
+		{
+			desc:   "missing character",
+			format: "b-b-dd",
+			words:  []string{"apple", "river", "stone"},
+			input:  "apple-river-4",
+			err:    ErrInvalidCode,
+		},
+		{
+			desc:   "words read in two ways",
+			format: "b-b",
+			words:  []string{"a", "ab", "ba"},
+			input:  "aba",
+			err:    ErrInvalidCode,
+		},
+		{
+			desc:   "words read in two ways that give the same code",
+			format: "bb",
+			words:  []string{"a", "ab", "ba"},
+			input:  "ABA",
+			want:   "aba",
+		},
+	}
+
+	for i, tt := range testCases {
+		Convey(fmt.Sprintf("Case # %d: %s", i, tt.desc), t, func() {
+
+			cf := New()
+			cf.SetFormat(tt.format)
+			cf.SetWords(tt.words)
+
+			got, err := cf.Normalize(tt.input)
+			So(err, ShouldEqual, tt.err)
+			So(got, ShouldEqual, tt.want)
+		})
+	}
+}